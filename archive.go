@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Archive keeps every published version of every file.
+// Contents are stored by their SHA-256 under objects/, while index.jsonl
+// records which contents were published at each path and when.
+type Archive struct {
+	root string
+}
+
+// OpenArchive opens the archive at root. Nothing is created until a file is stored,
+// so commands which only read or plan never leave an empty archive behind.
+func OpenArchive(root string) (*Archive, error) {
+	info, err := os.Stat(root)
+	if err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("archive %s is not a directory", root)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return &Archive{root: root}, nil
+}
+
+func (a *Archive) objectPath(sha string) string {
+	return filepath.Join(a.root, "objects", sha[:2], sha)
+}
+
+func (a *Archive) indexPath() string {
+	return filepath.Join(a.root, "index.jsonl")
+}
+
+// Store saves the contents of a file, unless identical contents are already archived.
+func (a *Archive) Store(data []byte) (string, error) {
+	sha := hashFile(data)
+	path := a.objectPath(sha)
+	if _, err := os.Stat(path); err == nil {
+		return sha, nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(path+".tmp", data, 0444)
+	if err != nil {
+		return "", err
+	}
+
+	return sha, os.Rename(path+".tmp", path)
+}
+
+// Load returns archived contents, verifying they have not been altered.
+func (a *Archive) Load(sha string) ([]byte, error) {
+	data, err := os.ReadFile(a.objectPath(sha))
+	if err != nil {
+		return nil, err
+	}
+
+	if hashFile(data) != sha {
+		return nil, fmt.Errorf("archived object %s is corrupt", sha)
+	}
+
+	return data, nil
+}
+
+// Record appends published files to the index.
+func (a *Archive) Record(entries []ManifestEntry) error {
+	err := os.MkdirAll(a.root, 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(a.indexPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if err = encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// AsOf returns the latest version of each path published at or before t, sorted by path.
+func (a *Archive) AsOf(t time.Time) ([]ManifestEntry, error) {
+	file, err := os.Open(a.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+	latest := map[string]ManifestEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry ManifestEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+
+		if entry.Generated.After(t) {
+			continue
+		}
+
+		if previous, ok := latest[entry.Path]; !ok || !entry.Generated.Before(previous.Generated) {
+			latest[entry.Path] = entry
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]ManifestEntry, 0, len(latest))
+	for _, entry := range latest {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveAsOf(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "archive")
+	archive, err := OpenArchive(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("opening an archive should not create it")
+	}
+
+	if entries, err := archive.AsOf(time.Now()); err != nil || len(entries) != 0 {
+		t.Errorf("expected a missing archive to be empty, got %v, %v", entries, err)
+	}
+
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	versions := [][]byte{[]byte("first"), []byte("second")}
+	for i, data := range versions {
+		sha, err := archive.Store(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = archive.Record([]ManifestEntry{{
+			Path:      "110/voting.bin",
+			Size:      len(data),
+			SHA256:    sha,
+			Generated: start.AddDate(0, 0, i),
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := archive.AsOf(start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].SHA256 != hashFile(versions[0]) {
+		t.Fatalf("expected the first version, got %+v", entries)
+	}
+
+	data, err := archive.Load(entries[0].SHA256)
+	if err != nil || string(data) != "first" {
+		t.Errorf("loaded %q, %v", data, err)
+	}
+
+	entries, err = archive.AsOf(start.AddDate(0, 0, 2))
+	if err != nil || len(entries) != 1 || entries[0].SHA256 != hashFile(versions[1]) {
+		t.Errorf("expected the second version, got %+v, %v", entries, err)
+	}
+
+	if entries, _ = archive.AsOf(start.Add(-time.Hour)); len(entries) != 0 {
+		t.Errorf("nothing was published before the first version, got %+v", entries)
+	}
+
+	// An altered object must not be republished.
+	path := archive.objectPath(hashFile(versions[0]))
+	if err = os.Chmod(path, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(path, []byte("altered"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = archive.Load(hashFile(versions[0])); err == nil {
+		t.Errorf("expected a corrupt object to fail to load")
+	}
+
+	if _, err = os.Stat(filepath.Join(archive.root, "index.jsonl")); err != nil {
+		t.Errorf("index was not written: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Checkpoint records the dates a backfill has completed for each locality,
+// allowing an interrupted run to resume where it stopped.
+type Checkpoint struct {
+	path      string
+	Completed map[string][]string `json:"completed"`
+}
+
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{path: path, Completed: map[string][]string{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, checkpoint)
+	if checkpoint.Completed == nil {
+		checkpoint.Completed = map[string][]string{}
+	}
+
+	return checkpoint, err
+}
+
+func (c *Checkpoint) IsComplete(locality evc.Locality, date time.Time) bool {
+	for _, completed := range c.Completed[locality.String()] {
+		if completed == date.Format(time.DateOnly) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Complete marks the date as done and saves the checkpoint.
+func (c *Checkpoint) Complete(locality evc.Locality, date time.Time) error {
+	dates := append(c.Completed[locality.String()], date.Format(time.DateOnly))
+	sort.Strings(dates)
+	c.Completed[locality.String()] = dates
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write then rename so an interrupted run never leaves a truncated checkpoint.
+	err = os.WriteFile(c.path+".tmp", data, 0666)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(c.path+".tmp", c.path)
+}
+
+// BackfillDates returns the dates results were published on between from and to inclusive.
+// National questions close on Tuesdays, Thursdays and Saturdays,
+// while worldwide questions close on the 1st and 16th of each month.
+func BackfillDates(locality evc.Locality, from time.Time, to time.Time) []time.Time {
+	var dates []time.Time
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		switch locality {
+		case evc.National:
+			weekday := date.Weekday()
+			if weekday == time.Tuesday || weekday == time.Thursday || weekday == time.Saturday {
+				dates = append(dates, date)
+			}
+		case evc.Worldwide:
+			if date.Day() == 1 || date.Day() == 16 {
+				dates = append(dates, date)
+			}
+		}
+	}
+
+	return dates
+}
+
+// RunBackfill regenerates historical results files between two dates.
+// An error is returned if any date failed, after every other date was attempted.
+func RunBackfill(args []string, key *rsa.PrivateKey, sink Sink, archive *Archive, workers int, options ResultOptions, dryRun bool, partial bool) error {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	fromStr := flags.String("from", "", "first date to backfill (YYYY-MM-DD)")
+	toStr := flags.String("to", time.Now().UTC().Format(time.DateOnly), "last date to backfill (YYYY-MM-DD)")
+	localityStr := flags.String("locality", "", "only backfill national (n) or worldwide (w) results")
+	countriesStr := flags.String("countries", "", "comma separated country codes to backfill (default all)")
+	checkpointPath := flags.String("checkpoint", "backfill.checkpoint.json", "file recording completed dates")
+	force := flags.Bool("force", false, "regenerate files that already exist")
+	checkError(flags.Parse(args))
+
+	if *fromStr == "" {
+		checkError(errors.New("backfill requires --from"))
+	}
+
+	from, err := time.Parse(time.DateOnly, *fromStr)
+	checkError(err)
+	to, err := time.Parse(time.DateOnly, *toStr)
+	checkError(err)
+
+	countryCodes, err := ParseCountryCodes(*countriesStr)
+	checkError(err)
+
+	localities := []evc.Locality{evc.National, evc.Worldwide}
+	if *localityStr != "" {
+		localities = []evc.Locality{GetLocality(*localityStr)}
+	}
+
+	checkpoint, err := LoadCheckpoint(*checkpointPath)
+	checkError(err)
+
+	ctx := context.Background()
+	pool := ConnectDatabase(ctx)
+	defer pool.Close()
+
+	failed := false
+	for _, locality := range localities {
+		dates := BackfillDates(locality, from, to)
+		for i, date := range dates {
+			progress := fmt.Sprintf("%d/%d", i+1, len(dates))
+			if checkpoint.IsComplete(locality, date) {
+				logger.Debug("backfill date already complete", "locality", locality, "date", date, "progress", progress)
+				continue
+			}
+
+			generator := NewGenerator(ctx, pool, key, evc.Results, locality, date)
+			generator.dryRun = dryRun
+			generator.options = options
+			report := NewReport(evc.Results, locality, date)
+
+			var pending []uint8
+			for _, countryCode := range countryCodes {
+				exists, err := sink.Exists(generator.GetPath(countryCode))
+				if err != nil {
+					return err
+				}
+
+				if exists && !*force {
+					report.Skipped(countryCode, "file already exists")
+					continue
+				}
+
+				pending = append(pending, countryCode)
+			}
+
+			err = nil
+			if len(pending) != 0 {
+				err = backfillDate(generator, pending, workers, report, key, sink, archive, partial)
+			}
+
+			report.Finish()
+			fmt.Printf("[%s] %s %s: %d succeeded, %d failed, %d skipped\n",
+				progress, date.Format(time.DateOnly), locality,
+				report.Count(StatusSucceeded), report.Count(StatusFailed), report.Count(StatusSkipped),
+			)
+
+			if err != nil || report.HasFailures() {
+				// Leave the date out of the checkpoint so it is retried on the next run.
+				failed = true
+				report.Print(os.Stdout)
+				continue
+			}
+
+			if dryRun {
+				report.PrintPlan(os.Stdout)
+				continue
+			}
+
+			checkError(checkpoint.Complete(locality, date))
+		}
+	}
+
+	if failed {
+		return errors.New("backfill did not complete, run it again to retry the failed dates")
+	}
+
+	return nil
+}
+
+// backfillDate generates and publishes the pending countries for a single date.
+func backfillDate(generator *Generator, pending []uint8, workers int, report *Report, key *rsa.PrivateKey, sink Sink, archive *Archive, partial bool) error {
+	if generator.dryRun {
+		return generator.Run(pending, workers, report)
+	}
+
+	publisher, err := NewPublisher(sink, &key.PublicKey, generator.currentTime)
+	if err != nil {
+		return err
+	}
+
+	defer publisher.Close()
+	publisher.archive = archive
+	generator.outputDir = publisher.Dir()
+
+	err = generator.Run(pending, workers, report)
+	if err != nil {
+		return err
+	}
+
+	err = publisher.Publish(report, nil, partial)
+	if err != nil {
+		return err
+	}
+
+	return generator.Quarantine(report)
+}
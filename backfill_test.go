@@ -0,0 +1,58 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackfillDates(t *testing.T) {
+	from := time.Date(2025, 4, 28, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC)
+
+	expected := map[evc.Locality][]string{
+		evc.National:  {"2025-04-29", "2025-05-01", "2025-05-03", "2025-05-06", "2025-05-08"},
+		evc.Worldwide: {"2025-05-01"},
+	}
+
+	for locality, dates := range expected {
+		actual := BackfillDates(locality, from, to)
+		if len(actual) != len(dates) {
+			t.Fatalf("%s: expected %d dates, got %d", locality, len(dates), len(actual))
+		}
+
+		for i, date := range dates {
+			if actual[i].Format(time.DateOnly) != date {
+				t.Errorf("%s: expected %s at %d, got %s", locality, date, i, actual[i].Format(time.DateOnly))
+			}
+		}
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	date := time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC)
+
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = checkpoint.Complete(evc.National, date); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !resumed.IsComplete(evc.National, date) {
+		t.Errorf("expected %s to be complete after resuming", date)
+	}
+
+	if resumed.IsComplete(evc.Worldwide, date) {
+		t.Errorf("completed dates must be tracked per locality")
+	}
+}
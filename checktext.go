@@ -0,0 +1,81 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CheckQuestionText substitutes characters the Wii cannot draw, then wraps a question as it will be published.
+// Translations too long to fit are removed and returned in dropped, so they fall back to another language.
+// It fails for any other character or field that cannot be shown.
+func CheckQuestionText(question *evc.Question, charset *evc.Charset, layouts evc.Layouts) (dropped []error, err error) {
+	if charset == nil {
+		charset = &evc.DefaultCharset
+	}
+
+	err = charset.CheckQuestion(question)
+	dropped, sanitizeErr := question.SanitizeText(layouts)
+	return dropped, errors.Join(err, sanitizeErr)
+}
+
+// RunCheckText checks questions as they would be generated, so they can be fixed when saved.
+func RunCheckText(args []string, currentTime time.Time, charset *evc.Charset, layouts evc.Layouts) {
+	flags := flag.NewFlagSet("check-text", flag.ExitOnError)
+	questionsStr := flags.String("questions", "", "comma separated question IDs to check (default those scheduled)")
+	weeks := flags.Int("weeks", 4, "number of weeks of scheduled questions to check")
+	checkError(flags.Parse(args))
+
+	ctx := context.Background()
+	pool := ConnectDatabase(ctx)
+	defer pool.Close()
+
+	var questions []evc.Question
+	if *questionsStr != "" {
+		questionIDs, err := ParseQuestionIDs(*questionsStr)
+		checkError(err)
+
+		for _, questionID := range questionIDs {
+			question, err := scanQuestion(pool.QueryRow(ctx, QueryQuestion, questionID))
+			checkError(err)
+			questions = append(questions, question)
+		}
+	} else {
+		rows, err := pool.Query(ctx, QueryScheduledQuestions, currentTime, currentTime.AddDate(0, 0, 7**weeks))
+		checkError(err)
+
+		for rows.Next() {
+			question, err := scanQuestion(rows)
+			checkError(err)
+			questions = append(questions, question)
+		}
+
+		rows.Close()
+		checkError(rows.Err())
+	}
+
+	failed := false
+	for _, question := range questions {
+		for _, err := range question.CheckJapanese() {
+			fmt.Printf("question %d: %v, English will be shown instead\n", question.ID, err)
+		}
+
+		// Text is checked before it is published, so translations which would fall back are failures here too.
+		dropped, err := CheckQuestionText(&question, charset, layouts)
+		if err = errors.Join(append(dropped, err)...); err != nil {
+			fmt.Println(err)
+			failed = true
+		}
+	}
+
+	if failed {
+		pool.Close()
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d questions can be shown\n", len(questions))
+}
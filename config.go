@@ -1,15 +1,165 @@
 package main
 
 import (
+	"EverybodyVotesChannel/evc"
 	"encoding/xml"
+	"fmt"
 	"io/ioutil"
+	"maps"
+	"strings"
 )
 
 type Config struct {
-	Username        string `xml:"username"`
-	Password        string `xml:"password"`
-	DatabaseAddress string `xml:"databaseAddress"`
-	DatabaseName    string `xml:"databaseName"`
+	Username        string   `xml:"username"`
+	Password        string   `xml:"password"`
+	DatabaseAddress string   `xml:"databaseAddress"`
+	DatabaseName    string   `xml:"databaseName"`
+	S3              S3Config `xml:"s3"`
+	// Fallbacks replace the chains of evc.DefaultFallbacks.
+	Fallbacks []FallbackConfig `xml:"fallbacks>fallback"`
+	// Characters adds to evc.DefaultCharset.
+	Characters CharsetConfig `xml:"charset"`
+	// Layout replaces the spaces of evc.DefaultLayouts.
+	Layout LayoutConfig `xml:"layout"`
+}
+
+// LayoutConfig sets the space question text and responses are wrapped to fit.
+type LayoutConfig struct {
+	Question *LayoutSize `xml:"question"`
+	Response *LayoutSize `xml:"response"`
+}
+
+// LayoutSize is a field's width in half width characters and its number of lines.
+type LayoutSize struct {
+	Width int `xml:"width,attr"`
+	Lines int `xml:"lines,attr"`
+}
+
+// CharsetConfig lists extra characters the Wii can draw, and extra substitutions for those it cannot.
+type CharsetConfig struct {
+	// Allow are characters such as U+20AC, or ranges such as U+0100-U+017F.
+	Allow         []string             `xml:"allow"`
+	Substitutions []SubstitutionConfig `xml:"substitute"`
+}
+
+// SubstitutionConfig replaces the character From with the text in the element, which may be empty.
+type SubstitutionConfig struct {
+	From string `xml:"from,attr"`
+	To   string `xml:",chardata"`
+}
+
+// S3Config holds the credentials for publishing to S3-compatible storage.
+type S3Config struct {
+	Endpoint  string `xml:"endpoint"`
+	Region    string `xml:"region"`
+	AccessKey string `xml:"accessKey"`
+	SecretKey string `xml:"secretKey"`
+}
+
+// FallbackConfig is the chain of languages tried when a question is not translated into Language.
+type FallbackConfig struct {
+	// Country limits the chain to a single country. It applies to every country if 0.
+	Country  uint8            `xml:"country,attr"`
+	Language evc.LanguageCode `xml:"language,attr"`
+	// Chain is a space separated list of language codes.
+	Chain string `xml:",chardata"`
+}
+
+// FallbackPolicy returns the configured fallback chains, adding to evc.DefaultFallbacks.
+func (c Config) FallbackPolicy() (*evc.FallbackPolicy, error) {
+	policy := &evc.FallbackPolicy{
+		Default:   maps.Clone(evc.DefaultFallbacks.Default),
+		Countries: map[uint8]map[evc.LanguageCode][]evc.LanguageCode{},
+	}
+
+	for _, fallback := range c.Fallbacks {
+		var chain []evc.LanguageCode
+		for _, code := range strings.Fields(fallback.Chain) {
+			language, err := evc.ParseLanguageCode(code)
+			if err != nil {
+				return nil, fmt.Errorf("fallback for %s: %w", fallback.Language, err)
+			}
+
+			chain = append(chain, language)
+		}
+
+		if fallback.Country == 0 {
+			policy.Default[fallback.Language] = chain
+			continue
+		}
+
+		if _, ok := evc.Countries[int(fallback.Country)]; !ok {
+			return nil, fmt.Errorf("fallback for %s: unknown country %d", fallback.Language, fallback.Country)
+		}
+
+		if policy.Countries[fallback.Country] == nil {
+			policy.Countries[fallback.Country] = map[evc.LanguageCode][]evc.LanguageCode{}
+		}
+
+		policy.Countries[fallback.Country][fallback.Language] = chain
+	}
+
+	return policy, nil
+}
+
+// Charset returns evc.DefaultCharset with the configured characters and substitutions added.
+func (c Config) Charset() (*evc.Charset, error) {
+	charset := evc.DefaultCharset.Clone()
+	for _, allow := range c.Characters.Allow {
+		first, last, isRange := strings.Cut(strings.TrimSpace(allow), "-")
+		if !isRange {
+			last = first
+		}
+
+		var charRange evc.CharRange
+		_, err := fmt.Sscanf(first, "U+%X", &charRange.First)
+		if err == nil {
+			_, err = fmt.Sscanf(last, "U+%X", &charRange.Last)
+		}
+
+		if err != nil || charRange.First > charRange.Last {
+			return nil, fmt.Errorf("invalid allowed characters %q", allow)
+		}
+
+		charset.Allowed = append(charset.Allowed, charRange)
+	}
+
+	for _, substitution := range c.Characters.Substitutions {
+		from := []rune(substitution.From)
+		if len(from) != 1 {
+			return nil, fmt.Errorf("substitution from %q must be a single character", substitution.From)
+		}
+
+		charset.Substitutions[from[0]] = substitution.To
+	}
+
+	return charset, nil
+}
+
+// Layouts returns evc.DefaultLayouts with any configured spaces replaced.
+func (c Config) Layouts() (evc.Layouts, error) {
+	layouts := evc.DefaultLayouts
+	for _, field := range []struct {
+		name   string
+		size   *LayoutSize
+		layout *evc.Layout
+	}{
+		{"question", c.Layout.Question, &layouts.Question},
+		{"response", c.Layout.Response, &layouts.Response},
+	} {
+		if field.size == nil {
+			continue
+		}
+
+		// A line must fit at least one full width character.
+		if field.size.Width < 2 || field.size.Lines < 1 {
+			return evc.Layouts{}, fmt.Errorf("invalid %s layout of %d lines of %d characters", field.name, field.size.Lines, field.size.Width)
+		}
+
+		*field.layout = evc.Layout{Width: field.size.Width, MaxLines: field.size.Lines}
+	}
+
+	return layouts, nil
 }
 
 func GetConfig() Config {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFallbackPolicy(t *testing.T) {
+	var config Config
+	err := xml.Unmarshal([]byte(`<Config><fallbacks>
+		<fallback language="pt">es</fallback>
+		<fallback country="18" language="fr-CA">fr de</fallback>
+	</fallbacks></Config>`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := config.FallbackPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain := policy.Chain(18, evc.FrenchCanadian); !slices.Equal(chain, []evc.LanguageCode{evc.FrenchCanadian, evc.French, evc.German, evc.English}) {
+		t.Errorf("unexpected chain %v", chain)
+	}
+
+	// Other countries keep the default chain.
+	if chain := policy.Chain(20, evc.FrenchCanadian); !slices.Equal(chain, []evc.LanguageCode{evc.FrenchCanadian, evc.French, evc.English}) {
+		t.Errorf("unexpected chain %v", chain)
+	}
+
+	if chain := policy.Chain(20, evc.Portuguese); !slices.Equal(chain, []evc.LanguageCode{evc.Portuguese, evc.Spanish, evc.English}) {
+		t.Errorf("unexpected chain %v", chain)
+	}
+
+	config.Fallbacks[0].Chain = "klingon"
+	if _, err = config.FallbackPolicy(); err == nil {
+		t.Errorf("expected an unknown language to be rejected")
+	}
+}
+
+func TestCharsetConfig(t *testing.T) {
+	var config Config
+	err := xml.Unmarshal([]byte(`<Config><charset>
+		<allow>U+0391-U+03C9</allow>
+		<allow>U+2122</allow>
+		<substitute from="«">"</substitute>
+		<substitute from="&#x00AD;"></substitute>
+	</charset></Config>`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	charset, err := config.Charset()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !charset.IsAllowed('Ω') || !charset.IsAllowed('™') || charset.IsAllowed('Ж') {
+		t.Errorf("unexpected allowed characters %v", charset.Allowed)
+	}
+
+	if text, invalid := charset.Apply("«Ωme\u00adga»"); text != "\"Ωmega»" || len(invalid) != 0 {
+		t.Errorf("got %q with invalid characters at %v", text, invalid)
+	}
+
+	// The defaults must not be changed.
+	if _, ok := evc.DefaultCharset.Substitutions['«']; ok || evc.DefaultCharset.IsAllowed('Ω') {
+		t.Errorf("configuration changed the default charset")
+	}
+
+	config.Characters.Allow = []string{"U+03C9-U+0391"}
+	if _, err = config.Charset(); err == nil {
+		t.Errorf("expected a reversed range to be rejected")
+	}
+}
+
+func TestMissingTranslations(t *testing.T) {
+	report := NewReport(evc.Questions, evc.National, time.Now())
+	report.AddFallbacks([]evc.Fallback{
+		{QuestionID: 9, CountryCode: 20, Field: "response1", Language: evc.FrenchCanadian, Used: evc.French},
+		{QuestionID: 4, CountryCode: 18, Field: "question", Language: evc.Japanese, Used: evc.English},
+		{QuestionID: 9, CountryCode: 18, Field: "question", Language: evc.FrenchCanadian, Used: evc.French},
+		{QuestionID: 9, CountryCode: 18, Field: "response1", Language: evc.FrenchCanadian, Used: evc.French},
+	})
+
+	var output strings.Builder
+	report.Print(&output)
+	expected := "question 4 is missing ja text for question (shown in en) in 018\n" +
+		"question 9 is missing fr-CA text for question, response1 (shown in fr) in 018, 020\n"
+	if !strings.HasSuffix(output.String(), expected) {
+		t.Errorf("unexpected report:\n%s", output.String())
+	}
+}
+
+func TestMismatchOrder(t *testing.T) {
+	report := NewReport(evc.Results, evc.National, time.Now())
+	report.AddMismatches([]Mismatch{{QuestionID: 7, CountryCode: 49}, {QuestionID: 3, CountryCode: 110}})
+	report.AddMismatches([]Mismatch{{QuestionID: 7, CountryCode: 18}})
+
+	var order []uint8
+	for _, mismatch := range report.Mismatches {
+		order = append(order, mismatch.CountryCode)
+	}
+
+	if !slices.Equal(order, []uint8{110, 18, 49}) {
+		t.Errorf("expected mismatches sorted by question then country, got %v", order)
+	}
+}
+
+func TestLayoutConfig(t *testing.T) {
+	var config Config
+	err := xml.Unmarshal([]byte(`<Config><layout><response width="30" lines="3"/></layout></Config>`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layouts, err := config.Layouts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if layouts.Question != evc.DefaultLayouts.Question || layouts.Response != (evc.Layout{Width: 30, MaxLines: 3}) {
+		t.Errorf("unexpected layouts %+v", layouts)
+	}
+
+	config.Layout.Question = &LayoutSize{Width: 1, Lines: 4}
+	if _, err = config.Layouts(); err == nil {
+		t.Errorf("expected a line too narrow for a full width character to be rejected")
+	}
+}
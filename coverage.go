@@ -0,0 +1,186 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// QueryScheduledQuestions queries the national and worldwide questions starting within a period.
+const QueryScheduledQuestions = `SELECT * FROM questions
+							WHERE date > $1
+							AND date <= $2
+							ORDER BY date, question_id`
+
+// CoverageStatus is how well a question is translated into a language.
+type CoverageStatus string
+
+const (
+	CoverageOK CoverageStatus = "ok"
+	// CoverageMissing is text that has not been translated.
+	CoverageMissing CoverageStatus = "missing"
+	// CoverageCopied is text identical to English. It may be a copy and paste, but names and words such as
+	// "OK" or "Pizza" are often the same, so it is a warning rather than a gap.
+	CoverageCopied CoverageStatus = "copied"
+	// CoverageTooLong is text which does not fit on screen once wrapped.
+	CoverageTooLong CoverageStatus = "too long"
+)
+
+// coverageSymbols are shown in the matrix so it stays narrow.
+var coverageSymbols = map[CoverageStatus]string{
+	CoverageOK:      ".",
+	CoverageMissing: "-",
+	CoverageCopied:  "=",
+	CoverageTooLong: "L",
+}
+
+// allLanguages are every language a question can be translated into.
+var allLanguages = []evc.LanguageCode{
+	evc.Japanese, evc.English, evc.German, evc.French, evc.Spanish,
+	evc.Italian, evc.Dutch, evc.Portuguese, evc.FrenchCanadian,
+}
+
+// TranslationStatus returns the worst status of the question's text and responses in the language.
+func TranslationStatus(question evc.Question, language evc.LanguageCode, layouts evc.Layouts) CoverageStatus {
+	worst := CoverageOK
+	for _, field := range []struct {
+		text   evc.LocalizedText
+		layout evc.Layout
+	}{
+		{question.QuestionText, layouts.Question},
+		{question.Response1, layouts.Response},
+		{question.Response2, layouts.Response},
+	} {
+		status := fieldStatus(field.text, language, field.layout)
+		if coverageRank(status) > coverageRank(worst) {
+			worst = status
+		}
+	}
+
+	return worst
+}
+
+func fieldStatus(text evc.LocalizedText, language evc.LanguageCode, layout evc.Layout) CoverageStatus {
+	translation := strings.TrimSpace(text.Translation(language))
+	switch {
+	case translation == "":
+		return CoverageMissing
+	case language != evc.English && translation == strings.TrimSpace(text.English):
+		return CoverageCopied
+	}
+
+	if _, err := layout.Wrap(translation); err != nil {
+		return CoverageTooLong
+	}
+
+	return CoverageOK
+}
+
+// coverageRank orders statuses from best to worst.
+func coverageRank(status CoverageStatus) int {
+	switch status {
+	case CoverageCopied:
+		return 1
+	case CoverageTooLong:
+		return 2
+	case CoverageMissing:
+		return 3
+	}
+
+	return 0
+}
+
+// LanguageWeights returns the number of countries which show questions in each language.
+func LanguageWeights() map[evc.LanguageCode]int {
+	weights := map[evc.LanguageCode]int{}
+	for _, countryCode := range evc.CountryCodes {
+		for _, language := range evc.GetSupportedLanguages(countryCode) {
+			weights[language]++
+		}
+	}
+
+	return weights
+}
+
+// RunCoverage prints the translation coverage of the questions scheduled after currentTime.
+func RunCoverage(args []string, currentTime time.Time, layouts evc.Layouts) {
+	flags := flag.NewFlagSet("coverage", flag.ExitOnError)
+	weeks := flags.Int("weeks", 4, "number of weeks of scheduled questions to check")
+	checkError(flags.Parse(args))
+
+	ctx := context.Background()
+	pool := ConnectDatabase(ctx)
+	defer pool.Close()
+
+	rows, err := pool.Query(ctx, QueryScheduledQuestions, currentTime, currentTime.AddDate(0, 0, 7**weeks))
+	checkError(err)
+	defer rows.Close()
+
+	var questions []evc.Question
+	for rows.Next() {
+		question, err := scanQuestion(rows)
+		checkError(err)
+		questions = append(questions, question)
+	}
+
+	checkError(rows.Err())
+	PrintCoverage(os.Stdout, questions, layouts)
+}
+
+// PrintCoverage writes a matrix of each question's status in each language.
+// Gaps are weighed by the number of countries which show the language.
+func PrintCoverage(writer io.Writer, questions []evc.Question, layouts evc.Layouts) {
+	weights := LanguageWeights()
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+
+	fmt.Fprint(table, "question\tdate")
+	for _, language := range allLanguages {
+		fmt.Fprintf(table, "\t%s", language)
+	}
+
+	fmt.Fprintln(table, "\tweighted gaps")
+
+	gaps := map[evc.LanguageCode]int{}
+	for _, question := range questions {
+		fmt.Fprintf(table, "%d\t%s", question.ID, question.Time.Format(time.DateOnly))
+
+		weighted := 0
+		for _, language := range allLanguages {
+			status := TranslationStatus(question, language, layouts)
+			if status != CoverageOK && status != CoverageCopied && weights[language] != 0 {
+				gaps[language]++
+				weighted += weights[language]
+			}
+
+			fmt.Fprintf(table, "\t%s", coverageSymbols[status])
+		}
+
+		fmt.Fprintf(table, "\t%d\n", weighted)
+	}
+
+	fmt.Fprint(table, "countries\t")
+	for _, language := range allLanguages {
+		fmt.Fprintf(table, "\t%d", weights[language])
+	}
+
+	fmt.Fprintln(table, "\t")
+
+	fmt.Fprint(table, "gaps\t")
+	for _, language := range allLanguages {
+		fmt.Fprintf(table, "\t%d", gaps[language])
+	}
+
+	fmt.Fprintln(table, "\t")
+	table.Flush()
+
+	fmt.Fprintf(writer, "\n%s ok, %s missing, %s same as English, %s too long once wrapped. Text the same as English is only a warning, "+
+		"as names and words such as OK often are. Languages no country shows are not counted as gaps.\n",
+		coverageSymbols[CoverageOK], coverageSymbols[CoverageMissing], coverageSymbols[CoverageCopied], coverageSymbols[CoverageTooLong],
+	)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranslationStatus(t *testing.T) {
+	question := evc.Question{
+		ID:           7,
+		QuestionText: evc.LocalizedText{English: "Cats or dogs?", German: "Katzen oder Hunde?", French: "Cats or dogs?", Dutch: "Katten of honden?"},
+		Response1:    evc.LocalizedText{English: "Cats", German: "Katzen", French: "Chats", Dutch: strings.Repeat("Katten ", 10)},
+		Response2:    evc.LocalizedText{English: "Dogs", German: "Hunde", French: "Chiens", Dutch: "Honden"},
+		Time:         time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	for language, expected := range map[evc.LanguageCode]CoverageStatus{
+		evc.English: CoverageOK,
+		evc.German:  CoverageOK,
+		evc.French:  CoverageCopied,
+		evc.Dutch:   CoverageTooLong,
+		evc.Spanish: CoverageMissing,
+	} {
+		if status := TranslationStatus(question, language, evc.DefaultLayouts); status != expected {
+			t.Errorf("%s: got %s, expected %s", language, status, expected)
+		}
+	}
+
+	var output strings.Builder
+	PrintCoverage(&output, []evc.Question{question}, evc.DefaultLayouts)
+
+	// Missing Spanish is shown in far more countries than missing Japanese.
+	weights := LanguageWeights()
+	if weights[evc.Japanese] != 1 || weights[evc.Spanish] <= weights[evc.Japanese] {
+		t.Errorf("unexpected weights %v", weights)
+	}
+
+	// French is the same as English, which is only a warning.
+	weighted := weights[evc.Japanese] + weights[evc.Spanish] + weights[evc.Italian] +
+		weights[evc.Dutch] + weights[evc.Portuguese] + weights[evc.FrenchCanadian]
+	fields := strings.Fields(strings.Split(output.String(), "\n")[1])
+	if strings.Join(fields, " ") != "7 2025-05-08 - . . = - - L - - "+strconv.Itoa(weighted) {
+		t.Errorf("unexpected row %q", fields)
+	}
+}
@@ -1,17 +1,20 @@
 package main
 
 import (
+	"EverybodyVotesChannel/evc"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v4"
-	"strconv"
-	"time"
+	"log/slog"
+	"math"
+	"slices"
 )
 
 const (
 	// QueryNationalQuestions queries the questions table for regular questions.
 	QueryNationalQuestions = `SELECT * FROM questions 
 							WHERE date > $1
-							AND date <= CURRENT_DATE
+							AND date <= $2
 							AND type = 'n'
 							ORDER BY date
 							LIMIT 3`
@@ -19,10 +22,13 @@ const (
 	// QueryQuestionsWorldwide queries the questions table for worldwide questions.
 	QueryQuestionsWorldwide = `SELECT * FROM questions 
          					WHERE date > $1
-           					AND date <= CURRENT_DATE
+           					AND date <= $2
            					AND type = 'w'
          					ORDER BY date`
 
+	// QueryQuestion queries a single question.
+	QueryQuestion = `SELECT * FROM questions WHERE question_id = $1`
+
 	// QueryApplicableNationalResults queries the questions table for national questions that have results.
 	QueryApplicableNationalResults = `SELECT question_id FROM questions
 							WHERE date <= $1
@@ -35,61 +41,84 @@ const (
   							AND type = 'w'
 							ORDER BY date DESC LIMIT 1`
 
-	QueryVoterData = `SELECT type_cd, region_id, ans_cnt FROM votes 
+	QueryVoterData = `SELECT type_cd, region_id, ` + tallyColumns + ` FROM votes 
                     WHERE question_id = $1 
                     AND country_id = $2`
 
-	QueryWorldwideVoterData = `SELECT type_cd, country_id, region_id, ans_cnt FROM votes 
+	QueryWorldwideVoterData = `SELECT type_cd, country_id, region_id, ` + tallyColumns + ` FROM votes 
                     WHERE question_id = $1`
+
+	// tallyColumns are the legacy ans_cnt column followed by the explicit counts added by sql/001_vote_tallies.sql.
+	tallyColumns = `ans_cnt, male_response1, male_response2, female_response1, female_response2,
+                    predictors_response1, predictors_response2`
 )
 
-type LocalizedText struct {
-	Japanese       string
-	English        string
-	German         string
-	French         string
-	Spanish        string
-	Italian        string
-	Dutch          string
-	Portuguese     string
-	FrenchCanadian string
+// tallyRow holds the tally columns of a row of the votes table.
+type tallyRow struct {
+	ansCnt *int64
+	counts [6]*int64
 }
 
-type Question struct {
-	ID           int
-	QuestionText LocalizedText
-	Response1    LocalizedText
-	Response2    LocalizedText
-	Category     int
-	Time         time.Time
+// targets returns the values to scan the tally columns into.
+func (r *tallyRow) targets() []any {
+	targets := []any{&r.ansCnt}
+	for i := range r.counts {
+		targets = append(targets, &r.counts[i])
+	}
+
+	return targets
 }
 
-var (
-	// Questions
-	nationalQuestions []Question
-	worldwideQuestion Question
+// Tally returns the explicit counts, or converts ans_cnt for rows which predate them.
+func (r *tallyRow) Tally(voteType evc.VoteType) (evc.Tally, error) {
+	if r.counts[0] == nil {
+		if r.ansCnt == nil {
+			return evc.Tally{}, errors.New("row has neither ans_cnt nor a tally")
+		}
 
-	// Results
-	worldWideDetailedResults []DetailedWorldwideResult
-	worldWideResult          WorldWideResult
-)
+		return evc.TallyFromAnsCnt(voteType, *r.ansCnt)
+	}
+
+	var counts [6]uint32
+	for i, count := range r.counts {
+		if count == nil || *count < 0 || *count > math.MaxUint32 {
+			return evc.Tally{}, fmt.Errorf("invalid tally column %d", i)
+		}
+
+		counts[i] = uint32(*count)
+	}
+
+	return evc.Tally{
+		MaleResponse1:       counts[0],
+		MaleResponse2:       counts[1],
+		FemaleResponse1:     counts[2],
+		FemaleResponse2:     counts[3],
+		PredictorsResponse1: counts[4],
+		PredictorsResponse2: counts[5],
+	}, nil
+}
 
 // PrepareWorldWideResults returns the WorldWideResult for the WorldWide vote,
 // as well as create a DetailedWorldwideResult slice.
-func PrepareWorldWideResults() {
+func (g *Generator) PrepareWorldWideResults() error {
 	var questionID int
 
 	// Worldwide polls run for 15 days. At the time this code will be executed, it should be 15 days after a
 	// poll has closed.
-	row := pool.QueryRow(ctx, QueryApplicableWorldwideResult, currentTime.AddDate(0, 0, -15))
+	row := g.pool.QueryRow(g.ctx, QueryApplicableWorldwideResult, g.currentTime.AddDate(0, 0, -15))
 	err := row.Scan(&questionID)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return
+		g.logger.Info("no worldwide result available", "time", g.currentTime)
+		return nil
 	}
 
-	checkError(err)
+	if err != nil {
+		return err
+	}
+
+	g.logger.Debug("preparing worldwide results", "question_id", questionID)
 
-	worldWideResult = WorldWideResult{
+	g.results.Worldwide = evc.WorldWideResult{
 		PollID:                          uint32(questionID),
 		MaleVotersResponse1:             0,
 		MaleVotersResponse2:             0,
@@ -102,84 +131,127 @@ func PrepareWorldWideResults() {
 	}
 
 	// Now that we know that there is a question, init the array
-	worldWideDetailedResults = make([]DetailedWorldwideResult, len(countryCodes)+1)
+	g.results.DetailedWorldwide = make([]evc.DetailedWorldwideResult, len(evc.CountryCodes)+1)
 
 	// Now we query votes table
-	rows, err := pool.Query(ctx, QueryWorldwideVoterData, questionID)
-	checkError(err)
+	rows, err := g.pool.Query(g.ctx, QueryWorldwideVoterData, questionID)
+	if err != nil {
+		return err
+	}
 
 	defer rows.Close()
+	mismatch := Mismatch{QuestionID: questionID, Reason: "unsupported country"}
 	for rows.Next() {
-		var typeCD VoteType
+		var typeCD evc.VoteType
 		var countryID int
 		var regionID int
-		var ansCNTInt int
+		var row tallyRow
+
+		err = rows.Scan(append([]any{&typeCD, &countryID, &regionID}, row.targets()...)...)
+		if err != nil {
+			return err
+		}
+
+		tally, err := row.Tally(typeCD)
+		if err != nil {
+			g.skipInvalidRow(questionID, uint8(countryID), err)
+			continue
+		}
 
-		err = rows.Scan(&typeCD, &countryID, &regionID, &ansCNTInt)
-		checkError(err)
+		if typeCD == evc.Vote && !slices.Contains(evc.CountryCodes, uint8(countryID)) {
+			mismatch.Rows++
+			mismatch.Voters += tally.Response1() + tally.Response2()
+			if g.quarantining() {
+				// Quarantined rows are left out so the total reconciles with the countries.
+				continue
+			}
+		}
 
-		ansCNT := FormatAnsCnt(strconv.FormatInt(int64(ansCNTInt), 10))
-		if typeCD == Vote {
-			// Main results
-			worldWideResult.MaleVotersResponse1 += ansCNT[0]
-			worldWideResult.MaleVotersResponse2 += ansCNT[2]
-			worldWideResult.FemaleVotersResponse1 += ansCNT[1]
-			worldWideResult.FemaleVotersResponse2 += ansCNT[3]
+		// Main results
+		g.results.Worldwide.AddTally(tally)
 
+		if typeCD == evc.Vote {
 			// Detailed Results
-			for i, code := range countryCodes {
+			for i, code := range evc.CountryCodes {
 				if code == uint8(countryID) {
-					worldWideDetailedResults[i].MaleVotersResponse1 += ansCNT[0]
-					worldWideDetailedResults[i].MaleVotersResponse2 += ansCNT[2]
-					worldWideDetailedResults[i].FemaleVotersResponse1 += ansCNT[1]
-					worldWideDetailedResults[i].FemaleVotersResponse2 += ansCNT[3]
-					worldWideDetailedResults[i].CountryTableCount = 7
+					g.results.DetailedWorldwide[i].AddTally(tally)
+					g.results.DetailedWorldwide[i].CountryTableCount = 7
 				}
 			}
-		} else if typeCD == Prediction {
-			worldWideResult.PredictorsResponse1 += ansCNT[0] + ansCNT[1]
-			worldWideResult.PredictorsResponse2 += ansCNT[2] + ansCNT[3]
 		}
 	}
 
-	countryTablePos := len(countryCodes) * 7
-	for i := len(countryCodes); i != -1; i-- {
-		if worldWideDetailedResults[i].CountryTableCount == 7 {
-			worldWideDetailedResults[i].CountryTableNumber = uint32(countryTablePos)
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	g.checkWorldwideResult(mismatch)
+
+	countryTablePos := len(evc.CountryCodes) * 7
+	for i := len(evc.CountryCodes); i != -1; i-- {
+		if g.results.DetailedWorldwide[i].CountryTableCount == 7 {
+			g.results.DetailedWorldwide[i].CountryTableNumber = uint32(countryTablePos)
 		} else {
 			// Remove the current country results from the array as it is null.
-			worldWideDetailedResults = append(worldWideDetailedResults[:i], worldWideDetailedResults[i+1:]...)
+			g.results.DetailedWorldwide = append(g.results.DetailedWorldwide[:i], g.results.DetailedWorldwide[i+1:]...)
 		}
 
 		countryTablePos -= 7
 	}
 
-	worldWideResult.NumberOfWorldWideDetailedTables = uint8(len(worldWideDetailedResults))
+	g.results.Worldwide.NumberOfWorldWideDetailedTables = uint8(len(g.results.DetailedWorldwide))
+
+	g.logger.Info("prepared worldwide results",
+		"question_id", questionID,
+		"countries", len(g.results.DetailedWorldwide),
+		"male_response1", g.results.Worldwide.MaleVotersResponse1,
+		"male_response2", g.results.Worldwide.MaleVotersResponse2,
+		"female_response1", g.results.Worldwide.FemaleVotersResponse1,
+		"female_response2", g.results.Worldwide.FemaleVotersResponse2,
+	)
+
+	return nil
 }
 
-func (v *Votes) PrepareNationalResults() ([]NationalResult, [][]DetailedNationalResult) {
-	var nationalResults []NationalResult
-	var detailedNationalResultsForResults [][]DetailedNationalResult
+// PrepareNationalResults tallies the applicable national results for a single country.
+func (g *Generator) PrepareNationalResults(countryCode uint8, logger *slog.Logger) ([]evc.NationalResult, [][]evc.DetailedNationalResult, error) {
+	var nationalResults []evc.NationalResult
+	var detailedNationalResultsForResults [][]evc.DetailedNationalResult
 
-	// First query for applicable results.
-	rows, err := pool.Query(ctx, QueryApplicableNationalResults, currentTime.AddDate(0, 0, -7))
+	// First query for applicable results. These are read in full before any voter data is queried,
+	// as holding two connections per country starves the pool when Countries are generated concurrently.
+	rows, err := g.pool.Query(g.ctx, QueryApplicableNationalResults, g.currentTime.AddDate(0, 0, -7))
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
+		return nil, nil, nil
 	}
 
-	checkError(err)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	index := 0
-	defer rows.Close()
+	var pollIDs []int
 	for rows.Next() {
-		// Now get voter data.
 		var questionID int
 		err = rows.Scan(&questionID)
-		checkError(err)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
 
+		pollIDs = append(pollIDs, questionID)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for index, questionID := range pollIDs {
+		// Now get voter data.
 		// Allocate space for the detailed results and the base result metadata
-		nationalDetailedResults := make([]DetailedNationalResult, numberOfRegions[v.currentCountryCode])
-		results := NationalResult{
+		nationalDetailedResults := make([]evc.DetailedNationalResult, evc.NumberOfRegions[countryCode])
+		results := evc.NationalResult{
 			PollID:                               uint32(questionID),
 			MaleVotersResponse1:                  0,
 			MaleVotersResponse2:                  0,
@@ -189,102 +261,228 @@ func (v *Votes) PrepareNationalResults() ([]NationalResult, [][]DetailedNational
 			PredictorsResponse2:                  0,
 			ShowVoterNumberFlag:                  1,
 			ShowDetailedResultsFlag:              0,
-			NationalResultDetailedNumber:         numberOfRegions[v.currentCountryCode],
-			StartingNationalResultDetailedNumber: uint32(numberOfRegions[v.currentCountryCode] * uint8(index)),
+			NationalResultDetailedNumber:         evc.NumberOfRegions[countryCode],
+			StartingNationalResultDetailedNumber: uint32(evc.NumberOfRegions[countryCode] * uint8(index)),
 		}
 
-		voterRows, err := pool.Query(ctx, QueryVoterData, questionID, v.currentCountryCode)
-		checkError(err)
+		votes, err := g.tallyNationalVotes(questionID, countryCode)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		for voterRows.Next() {
-			var typeCD VoteType
-			var regionID int
-			var ansCNTInt int
+		_, hasPositions := evc.PositionTable[countryCode]
 
-			err = voterRows.Scan(&typeCD, &regionID, &ansCNTInt)
-			checkError(err)
+		// Show the country map if we got a position table
+		if votes.Rows != 0 && hasPositions {
+			results.ShowDetailedResultsFlag = 1
+		}
 
-			// Show the country map if we got a position table
-			if _, ok := positionTable[v.currentCountryCode]; ok {
-				results.ShowDetailedResultsFlag = 1
-			}
+		// Main results
+		for _, tally := range votes.Predictions {
+			results.AddTally(tally)
+		}
 
-			ansCNT := FormatAnsCnt(strconv.FormatInt(int64(ansCNTInt), 10))
-			if typeCD == Vote {
-				// Main results
-				results.MaleVotersResponse1 += ansCNT[0]
-				results.MaleVotersResponse2 += ansCNT[2]
-				results.FemaleVotersResponse1 += ansCNT[1]
-				results.FemaleVotersResponse2 += ansCNT[3]
-
-				for i := 0; i < int(numberOfRegions[v.currentCountryCode]); i++ {
-					// Nintendo made the region ID start at index 1, with that being the country.
-					if i == regionID-2 {
-						nationalDetailedResults[i].VotersResponse1Number += ansCNT[0] + ansCNT[1]
-						nationalDetailedResults[i].VotersResponse2Number += ansCNT[2] + ansCNT[3]
-						if _, ok := positionTable[v.currentCountryCode]; ok {
-							nationalDetailedResults[i].PositionEntryTableCount = positionTable[v.currentCountryCode][i]
-						} else {
-							nationalDetailedResults[i].PositionEntryTableCount = 0
-						}
-					}
-					if _, ok := positionTable[v.currentCountryCode]; ok {
-						nationalDetailedResults[i].PositionTableEntryNumber = uint32(sum(positionTable[v.currentCountryCode][:i]))
-					}
+		// countryVoters voted from region 1, the country as a whole, so count towards the total but no region.
+		var countryVoters uint32
+		for regionID, tally := range votes.Votes {
+			results.AddTally(tally)
+
+			// Nintendo made the region ID start at index 1, with that being the country.
+			i := regionID - 2
+			switch {
+			case regionID == 1:
+				countryVoters += tally.Response1() + tally.Response2()
+			case i >= 0 && i < len(nationalDetailedResults):
+				nationalDetailedResults[i].AddTally(tally)
+				if hasPositions {
+					nationalDetailedResults[i].PositionEntryTableCount = evc.PositionTable[countryCode][i]
 				}
-			} else if typeCD == Prediction {
-				results.PredictorsResponse1 += ansCNT[0] + ansCNT[1]
-				results.PredictorsResponse2 += ansCNT[2] + ansCNT[3]
 			}
 		}
 
-		index++
+		if len(votes.Votes) != 0 && hasPositions {
+			for i := range nationalDetailedResults {
+				nationalDetailedResults[i].PositionTableEntryNumber = uint32(sum(evc.PositionTable[countryCode][:i]))
+			}
+		}
+
+		g.checkNationalResult(votes.Mismatch, results, nationalDetailedResults, countryVoters)
+
+		logger.Debug("prepared national result",
+			"question_id", questionID,
+			"rows", votes.Rows,
+			"show_detailed_results", results.ShowDetailedResultsFlag,
+			"male_response1", results.MaleVotersResponse1,
+			"male_response2", results.MaleVotersResponse2,
+			"female_response1", results.FemaleVotersResponse1,
+			"female_response2", results.FemaleVotersResponse2,
+		)
+
 		nationalResults = append(nationalResults, results)
 		detailedNationalResultsForResults = append(detailedNationalResultsForResults, nationalDetailedResults)
-		voterRows.Close()
 
-		if fileType == Results {
+		if g.fileType == evc.Results {
 			// Only one result is required for this file type.
 			break
 		}
 	}
 
-	return nationalResults, detailedNationalResultsForResults
+	return nationalResults, detailedNationalResultsForResults, nil
+}
+
+// nationalVotes are the votes and predictions for a question in a country, tallied by region ID.
+type nationalVotes struct {
+	// Votes are keyed by region ID, where region 1 is the country as a whole.
+	// Votes from regions the country does not have are only included while they are not being quarantined.
+	Votes       map[int]evc.Tally
+	Predictions map[int]evc.Tally
+	// Rows is the number of rows included.
+	Rows     int
+	Mismatch Mismatch
+}
+
+// tallyNationalVotes reads the votes for a question in a country. It is shared by PrepareNationalResults
+// and export-results, so exported results always match the published files.
+func (g *Generator) tallyNationalVotes(questionID int, countryCode uint8) (nationalVotes, error) {
+	votes := nationalVotes{
+		Votes:       map[int]evc.Tally{},
+		Predictions: map[int]evc.Tally{},
+		Mismatch:    Mismatch{QuestionID: questionID, CountryCode: countryCode, Reason: "invalid region"},
+	}
+
+	rows, err := g.pool.Query(g.ctx, QueryVoterData, questionID, countryCode)
+	if err != nil {
+		return votes, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var typeCD evc.VoteType
+		var regionID int
+		var row tallyRow
+
+		err = rows.Scan(append([]any{&typeCD, &regionID}, row.targets()...)...)
+		if err != nil {
+			return votes, err
+		}
+
+		tally, err := row.Tally(typeCD)
+		if err != nil {
+			g.skipInvalidRow(questionID, countryCode, err)
+			continue
+		}
+
+		if typeCD != evc.Vote {
+			votes.Predictions[regionID] = votes.Predictions[regionID].Add(tally)
+			votes.Rows++
+			continue
+		}
+
+		if regionID < 1 || regionID-2 >= int(evc.NumberOfRegions[countryCode]) {
+			votes.Mismatch.Rows++
+			votes.Mismatch.Voters += tally.Response1() + tally.Response2()
+			if g.quarantining() {
+				// Quarantined rows are left out so the total reconciles with the regions.
+				continue
+			}
+		}
+
+		votes.Votes[regionID] = votes.Votes[regionID].Add(tally)
+		votes.Rows++
+	}
+
+	return votes, rows.Err()
 }
 
-func PrepareNationalQuestions() {
-	rows, err := pool.Query(ctx, QueryNationalQuestions, currentTime.AddDate(0, 0, -7))
-	checkError(err)
+func (g *Generator) PrepareNationalQuestions() error {
+	rows, err := g.pool.Query(g.ctx, QueryNationalQuestions, g.currentTime.AddDate(0, 0, -7), g.currentTime)
+	if err != nil {
+		return err
+	}
 
 	defer rows.Close()
 	for rows.Next() {
-		question := Question{}
-		err = rows.Scan(&question.ID,
-			&question.QuestionText.English, &question.QuestionText.German, &question.QuestionText.French,
-			&question.QuestionText.Spanish, &question.QuestionText.Italian, &question.QuestionText.Dutch,
-			&question.QuestionText.Portuguese, &question.QuestionText.FrenchCanadian,
-			&question.Response1.English, &question.Response1.German, &question.Response1.French,
-			&question.Response1.Spanish, &question.Response1.Italian, &question.Response1.Dutch,
-			&question.Response1.Portuguese, &question.Response1.FrenchCanadian,
-			&question.Response2.English, &question.Response2.German, &question.Response2.French,
-			&question.Response2.Spanish, &question.Response2.Italian, &question.Response2.Dutch,
-			&question.Response2.Portuguese, &question.Response2.FrenchCanadian, nil, &question.Category,
-			&question.Time,
-		)
-		checkError(err)
+		question, err := scanQuestion(rows)
+		if err != nil {
+			return err
+		}
 
-		// Apply wordwrap for each question
-		question.SanitizeText()
+		if err = g.prepareText(&question); err != nil {
+			return err
+		}
 
 		// Finally append to the list of national questions.
-		nationalQuestions = append(nationalQuestions, question)
+		g.questions.National = append(g.questions.National, question)
+		g.logger.Debug("loaded question", "question_id", question.ID, "date", question.Time)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
 	}
+
+	g.logger.Info("prepared national questions", "question_ids", questionIDs(g.questions.National))
+	return nil
 }
 
-func PrepareWorldWideQuestion() {
-	row := pool.QueryRow(ctx, QueryQuestionsWorldwide, currentTime.AddDate(0, 0, -15))
+func (g *Generator) PrepareWorldWideQuestion() error {
+	row := g.pool.QueryRow(g.ctx, QueryQuestionsWorldwide, g.currentTime.AddDate(0, 0, -15), g.currentTime)
+
+	question, err := scanQuestion(row)
+	if err != nil {
+		return err
+	}
+
+	if err = g.prepareText(&question); err != nil {
+		return err
+	}
+
+	// Finally assign as our worldwide question.
+	g.questions.Worldwide = question
+	g.logger.Info("prepared worldwide question", "question_id", question.ID, "date", question.Time)
+	return nil
+}
+
+// prepareText readies a question's text for the Wii.
+// Characters the font cannot draw are shown as "?" and reported, so a single character does not stop the run.
+// Text that would be clipped fails the run rather than be published.
+// With fallbackLongText, translations too long to fit fall back to another language instead.
+func (g *Generator) prepareText(question *evc.Question) error {
+	g.checkJapanese(question)
+	charset := g.charset
+	if charset == nil {
+		charset = &evc.DefaultCharset
+	}
+
+	for _, replacedErr := range charset.ReplaceQuestion(question, "?") {
+		g.logger.Warn("character cannot be drawn, showing ? instead", "question_id", question.ID, "error", replacedErr)
+		g.replaced = append(g.replaced, replacedErr.Error())
+	}
 
-	question := Question{}
+	dropped, err := question.SanitizeText(g.layouts)
+	if !g.fallbackLongText {
+		return errors.Join(append(dropped, err)...)
+	}
+
+	for _, droppedErr := range dropped {
+		g.logger.Warn("translation does not fit, falling back", "question_id", question.ID, "error", droppedErr)
+		g.dropped = append(g.dropped, droppedErr.Error())
+	}
+
+	return err
+}
+
+// checkJapanese warns about Japanese text that cannot be shown, which is then shown in English.
+func (g *Generator) checkJapanese(question *evc.Question) {
+	for _, err := range question.CheckJapanese() {
+		g.logger.Warn("falling back to English", "question_id", question.ID, "error", err)
+	}
+}
+
+// scanQuestion reads a row of the questions table.
+// The Japanese columns were added by sql/003_japanese_text.sql and are NULL until translated.
+func scanQuestion(row pgx.Row) (evc.Question, error) {
+	question := evc.Question{}
+	var japanese [3]*string
 	err := row.Scan(&question.ID,
 		&question.QuestionText.English, &question.QuestionText.German, &question.QuestionText.French,
 		&question.QuestionText.Spanish, &question.QuestionText.Italian, &question.QuestionText.Dutch,
@@ -295,13 +493,14 @@ func PrepareWorldWideQuestion() {
 		&question.Response2.English, &question.Response2.German, &question.Response2.French,
 		&question.Response2.Spanish, &question.Response2.Italian, &question.Response2.Dutch,
 		&question.Response2.Portuguese, &question.Response2.FrenchCanadian, nil, &question.Category,
-		&question.Time,
+		&question.Time, &japanese[0], &japanese[1], &japanese[2],
 	)
-	checkError(err)
 
-	// Apply wordwrap for each question
-	question.SanitizeText()
+	for i, text := range []*string{&question.QuestionText.Japanese, &question.Response1.Japanese, &question.Response2.Japanese} {
+		if japanese[i] != nil {
+			*text = *japanese[i]
+		}
+	}
 
-	// Finally assign as our worldwide question.
-	worldwideQuestion = question
+	return question, err
 }
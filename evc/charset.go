@@ -0,0 +1,165 @@
+package evc
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// CharRange is an inclusive range of characters.
+type CharRange struct {
+	First rune
+	Last  rune
+}
+
+// Charset is the characters the Wii system font can draw,
+// with replacements for common characters that it cannot.
+type Charset struct {
+	Allowed       []CharRange
+	Substitutions map[rune]string
+}
+
+// DefaultCharset covers Latin text in every supported language and Japanese.
+// The ranges have not been checked against the font on a Wii, so they may leave out characters it can draw.
+var DefaultCharset = Charset{
+	Allowed: []CharRange{
+		{'\n', '\n'},
+		{0x20, 0x7e},
+		// Latin-1 Supplement and Latin Extended-A
+		{0xa0, 0x17f},
+		{0x20ac, 0x20ac},
+		// Full width punctuation, hiragana and katakana
+		{0x3000, 0x30ff},
+		{0x4e00, 0x9fff},
+		// Full width Latin and half width katakana
+		{0xff01, 0xff9f},
+	},
+	Substitutions: map[rune]string{
+		'‘': "'",
+		'’': "'",
+		'“': "\"",
+		'”': "\"",
+		'–': "-",
+		'—': "-",
+		'…': "...",
+		// Zero width space
+		'\u200b': "",
+	},
+}
+
+// CharsetError is a character in a question which the Wii cannot draw.
+type CharsetError struct {
+	QuestionID int
+	Language   LanguageCode
+	Field      string
+	// Position counts characters from 1.
+	Position int
+	Char     rune
+}
+
+func (e CharsetError) Error() string {
+	return fmt.Sprintf("question %d %s %s: character %d %U %q is not in the Wii font", e.QuestionID, e.Language, e.Field, e.Position, e.Char, e.Char)
+}
+
+// Clone returns a copy of the charset which can be extended.
+func (c *Charset) Clone() *Charset {
+	return &Charset{Allowed: slices.Clone(c.Allowed), Substitutions: maps.Clone(c.Substitutions)}
+}
+
+// IsAllowed reports whether the font can draw a character.
+func (c *Charset) IsAllowed(r rune) bool {
+	for _, allowed := range c.Allowed {
+		if r >= allowed.First && r <= allowed.Last {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply replaces the substituted characters of text, and returns the positions of any that cannot be drawn.
+// Characters outside the Basic Multilingual Plane are never allowed, as they are written as surrogate pairs.
+func (c *Charset) Apply(text string) (string, []int) {
+	var builder strings.Builder
+	var invalid []int
+	position := 0
+	for _, r := range text {
+		position++
+		if substitute, ok := c.Substitutions[r]; ok {
+			builder.WriteString(substitute)
+			continue
+		}
+
+		if r > 0xffff || !c.IsAllowed(r) {
+			invalid = append(invalid, position)
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return builder.String(), invalid
+}
+
+// CheckQuestion substitutes characters in every translation of a question,
+// returning a CharsetError for each character left that cannot be drawn.
+func (c *Charset) CheckQuestion(q *Question) error {
+	return errors.Join(c.checkQuestion(q, nil)...)
+}
+
+// ReplaceQuestion substitutes characters in every translation of a question as CheckQuestion does,
+// then replaces each character left that cannot be drawn with replacement, returning a CharsetError for each.
+func (c *Charset) ReplaceQuestion(q *Question, replacement string) []error {
+	return c.checkQuestion(q, &replacement)
+}
+
+func (c *Charset) checkQuestion(q *Question, replacement *string) []error {
+	var errs []error
+	for _, field := range []struct {
+		name string
+		text *LocalizedText
+	}{
+		{"question", &q.QuestionText},
+		{"response 1", &q.Response1},
+		{"response 2", &q.Response2},
+	} {
+		for language := Japanese; language <= FrenchCanadian; language++ {
+			text := field.text.translation(language)
+			original := []rune(*text)
+
+			var invalid []int
+			*text, invalid = c.Apply(*text)
+			for _, position := range invalid {
+				errs = append(errs, CharsetError{
+					QuestionID: q.ID,
+					Language:   language,
+					Field:      field.name,
+					Position:   position,
+					Char:       original[position-1],
+				})
+			}
+
+			if replacement != nil && len(invalid) != 0 {
+				*text = c.replaceInvalid(*text, *replacement)
+			}
+		}
+	}
+
+	return errs
+}
+
+// replaceInvalid replaces the characters of text which cannot be drawn.
+func (c *Charset) replaceInvalid(text string, replacement string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		if r > 0xffff || !c.IsAllowed(r) {
+			builder.WriteString(replacement)
+			continue
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,50 @@
+package evc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCharset(t *testing.T) {
+	question := Question{
+		ID:           6,
+		QuestionText: LocalizedText{English: "“Cats” or dogs…", French: "Chats ou chiens ?", Japanese: "ネコとイヌ、どっちが好き？"},
+		Response1:    LocalizedText{English: "Cats 🐈", German: "Katzen – ja"},
+	}
+
+	err := DefaultCharset.CheckQuestion(&question)
+
+	var charsetErr CharsetError
+	if !errors.As(err, &charsetErr) {
+		t.Fatalf("expected a CharsetError, got %v", err)
+	}
+
+	expected := CharsetError{QuestionID: 6, Language: English, Field: "response 1", Position: 6, Char: '🐈'}
+	if charsetErr != expected {
+		t.Errorf("got %+v, expected %+v", charsetErr, expected)
+	}
+
+	if question.QuestionText.English != "\"Cats\" or dogs..." || question.Response1.German != "Katzen - ja" {
+		t.Errorf("substitutions were not applied: %q, %q", question.QuestionText.English, question.Response1.German)
+	}
+
+	if question.QuestionText.Japanese != "ネコとイヌ、どっちが好き？" {
+		t.Errorf("Japanese should be left as it is, got %q", question.QuestionText.Japanese)
+	}
+}
+
+func TestReplaceQuestion(t *testing.T) {
+	question := Question{
+		ID:        7,
+		Response1: LocalizedText{English: "★ Stars ★", French: "Étoiles"},
+	}
+
+	errs := DefaultCharset.ReplaceQuestion(&question, "?")
+	if len(errs) != 2 {
+		t.Errorf("expected both stars to be reported, got %v", errs)
+	}
+
+	if question.Response1.English != "? Stars ?" || question.Response1.French != "Étoiles" {
+		t.Errorf("unexpected replaced text %q, %q", question.Response1.English, question.Response1.French)
+	}
+}
@@ -1,7 +1,12 @@
-package main
+package evc
 
-// countryCodes is a list of supported countries.
-var countryCodes = []uint8{
+import (
+	"fmt"
+	"strings"
+)
+
+// CountryCodes is a list of supported countries.
+var CountryCodes = []uint8{
 	1,
 	10,
 	16,
@@ -38,8 +43,8 @@ var countryCodes = []uint8{
 	110,
 }
 
-// numberOfRegions is the amount of provinces/states/prefectures each country has
-var numberOfRegions = map[uint8]uint8{
+// NumberOfRegions is the amount of provinces/states/prefectures each country has
+var NumberOfRegions = map[uint8]uint8{
 	1:   47,
 	10:  24,
 	16:  27,
@@ -76,11 +81,11 @@ var numberOfRegions = map[uint8]uint8{
 	110: 5,
 }
 
-// languages are all the languages the Everybody Votes Channel supports.
-var languages = []LanguageCode{Japanese, English, German, French, Spanish, Italian, Dutch}
+// Languages are all the languages the Everybody Votes Channel supports.
+var Languages = []LanguageCode{Japanese, English, German, French, Spanish, Italian, Dutch}
 
-// countries are all the countries EVC supports in all languages.
-var countries = map[int][]string{
+// Countries are all the countries EVC supports in all languages.
+var Countries = map[int][]string{
 	1:   {"日本", "Japan", "Japan", "Japon", "Japón", "Giappone", "Japan"},
 	10:  {"アルゼンチン", "Argentina", "Argentinien", "Argentine", "Argentina", "Argentina", "Argentinië"},
 	16:  {"ブラジル", "Brazil", "Brasilien", "Brésil", "Brasil", "Brasile", "Brazilië"},
@@ -117,8 +122,8 @@ var countries = map[int][]string{
 	110: {"イギリス", "United Kingdom", "Großbritannien", "Royaume-Uni", "Reino Unido", "Regno Unito", "Verenigd Koninkrijk"},
 }
 
-// countriesSupportedLanguages is a list of languages each country supports.
-var countriesSupportedLanguages = map[uint8][]LanguageCode{
+// CountriesSupportedLanguages is a list of languages each country supports.
+var CountriesSupportedLanguages = map[uint8][]LanguageCode{
 	1:   {Japanese, English},
 	10:  {English, Spanish, FrenchCanadian},
 	16:  {English, Spanish, Portuguese, FrenchCanadian},
@@ -155,11 +160,11 @@ var countriesSupportedLanguages = map[uint8][]LanguageCode{
 	110: {English},
 }
 
-var positionTable = map[uint8][]uint8{
+var PositionTable = map[uint8][]uint8{
 	1:   {1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2},
 	16:  {1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 1, 1, 1, 1, 1, 1},
 	18:  {1, 1, 2, 1, 1, 3, 1, 1, 1, 1, 1, 4, 3},
-	21:  {1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 1, 1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0},
+	21:  {1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 1, 1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0},
 	36:  {1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
 	40:  {2, 0, 1, 1, 1, 0, 0, 1, 1, 2},
 	49:  {1, 3, 1, 1, 1, 1, 1, 1, 1, 1, 1, 3, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
@@ -171,9 +176,9 @@ var positionTable = map[uint8][]uint8{
 	110: {1, 2, 2, 1, 1},
 }
 
-// positionData is a list of data for the position table.
+// PositionData is a list of data for the position table.
 // It has something to do with the position of votes on the downloaded map.
-var positionData = map[int]string{
+var PositionData = map[int]string{
 	1:   "A2A4C828AF52B964B478AA64AA73AA87AD9BA5969B96A09EADA5A2A987947F8E78A096A5919B9B8782A591AF82AF7AB978AA6EAA6DB364AF73B96BC05AA546AA55AF4BB437B95FC358BA46C350C82DBE26C623CD2DD237C837D728E14849395A",
 	16:  "A4862664E8648E1E4141C873D746CD9E7DA0B4467878B99B8746E35385BEC855C2AEE94D82DC4B6996C8A5AAE3699687E15AA064",
 	18:  "87BE3CA009981EA064AAC8C3F0A8E1AAC89BD7C3D4BDAAAA50AF1E695C405649505A3C787841647D8E89",
@@ -189,7 +194,7 @@ var positionData = map[int]string{
 	110: "B4B4738732E67846D71E82B4507D",
 }
 
-var categoryKV = map[int]uint8{
+var CategoryKV = map[int]uint8{
 	0: 3,
 	1: 5,
 	2: 7,
@@ -228,7 +233,7 @@ type FileType uint8
 const (
 	Normal FileType = iota
 	Results
-	_Question
+	Questions
 )
 
 // Locality is whether it is national or worldwide
@@ -239,3 +244,81 @@ const (
 	National
 	All
 )
+
+func (l LanguageCode) String() string {
+	switch l {
+	case Japanese:
+		return "ja"
+	case English:
+		return "en"
+	case German:
+		return "de"
+	case French:
+		return "fr"
+	case Spanish:
+		return "es"
+	case Italian:
+		return "it"
+	case Dutch:
+		return "nl"
+	case Portuguese:
+		return "pt"
+	case FrenchCanadian:
+		return "fr-CA"
+	}
+
+	return "unknown"
+}
+
+// MarshalText writes the language as the code returned by String.
+func (l LanguageCode) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText reads a language written by MarshalText.
+func (l *LanguageCode) UnmarshalText(text []byte) error {
+	language, err := ParseLanguageCode(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = language
+	return nil
+}
+
+// ParseLanguageCode returns the language with the code returned by String.
+func ParseLanguageCode(code string) (LanguageCode, error) {
+	for language := Japanese; language <= FrenchCanadian; language++ {
+		if strings.EqualFold(language.String(), code) {
+			return language, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown language %q", code)
+}
+
+func (f FileType) String() string {
+	switch f {
+	case Normal:
+		return "voting"
+	case Results:
+		return "results"
+	case Questions:
+		return "question"
+	}
+
+	return "unknown"
+}
+
+func (l Locality) String() string {
+	switch l {
+	case Worldwide:
+		return "worldwide"
+	case National:
+		return "national"
+	case All:
+		return "all"
+	}
+
+	return "unknown"
+}
@@ -1,6 +1,7 @@
-package main
+package evc
 
 import (
+	"sort"
 	"unicode/utf16"
 )
 
@@ -11,8 +12,8 @@ type CountryInfoTable struct {
 
 func (v *Votes) MakeCountryInfoTable() {
 	v.Header.CountryTableOffset = v.GetCurrentSize()
-	for range countries {
-		for _, code := range languages {
+	for range Countries {
+		for _, code := range Languages {
 			country := CountryInfoTable{
 				LanguageCode: code,
 				TextOffset:   0,
@@ -26,10 +27,17 @@ func (v *Votes) MakeCountryInfoTable() {
 }
 
 func (v *Votes) MakeCountryTable() {
-	i := 0
+	// Countries are written in order of their code, as map iteration order is random.
+	codes := make([]int, 0, len(Countries))
+	for code := range Countries {
+		codes = append(codes, code)
+	}
 
-	for _, strings := range countries {
-		for _, country := range strings {
+	sort.Ints(codes)
+
+	i := 0
+	for _, code := range codes {
+		for _, country := range Countries[code] {
 			v.CountryInfoTable[i].TextOffset = v.GetCurrentSize()
 			v.CountryTable = append(v.CountryTable, utf16.Encode([]rune(country))...)
 
@@ -0,0 +1,151 @@
+package evc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Decode parses an encoded file, as returned by Encode or Unpack, back into its tables.
+func Decode(data []byte) (*Votes, error) {
+	votes := &Votes{}
+	reader := decoder{data: data}
+
+	reader.read(0, &votes.Header)
+	header := votes.Header
+	if reader.err == nil && int(header.Filesize) != len(data) {
+		return nil, fmt.Errorf("file is %d bytes but the header says %d", len(data), header.Filesize)
+	}
+
+	votes.NationalQuestionTable = make([]QuestionInfo, header.NumberOfNationalQuestions)
+	reader.read(header.NationalQuestionTableOffset, votes.NationalQuestionTable)
+	votes.WorldWideQuestionTable = make([]QuestionInfo, header.NumberOfWorldWideQuestions)
+	reader.read(header.WorldWideQuestionTableOffset, votes.WorldWideQuestionTable)
+	votes.QuestionTextInfoTable = make([]QuestionTextInfo, header.NumberOfQuestions)
+	reader.read(header.QuestionTextInfoTableOffset, votes.QuestionTextInfoTable)
+
+	for _, info := range votes.QuestionTextInfoTable {
+		votes.QuestionText = append(votes.QuestionText, QuestionText{
+			Question:  reader.text(info.QuestionOffset),
+			Response1: reader.text(info.Response1Offset),
+			Response2: reader.text(info.Response2Offset),
+		})
+	}
+
+	votes.NationalResults = make([]NationalResult, header.NumberOfNationalResults)
+	reader.read(header.NationalResultTableOffset, votes.NationalResults)
+	votes.DetailedNationalResults = make([]DetailedNationalResult, header.NumberOfDetailedNationalResults)
+	reader.read(header.DetailedNationalResultTableOffset, votes.DetailedNationalResults)
+
+	if header.NumberOfPositionTables != 0 {
+		// The position table's size is not in the header, so it runs until the next table.
+		end := uint32(len(data))
+		for _, offset := range []uint32{header.WorldWideResultsTableOffset, header.DetailedWorldWideResultTableOffset, header.CountryTableOffset} {
+			if offset > header.PositionTableOffset && offset < end {
+				end = offset
+			}
+		}
+
+		votes.PositionEntryTable = reader.bytes(header.PositionTableOffset, end)
+	}
+
+	votes.WorldwideResults = make([]WorldWideResult, header.NumberOfWorldWideResults)
+	reader.read(header.WorldWideResultsTableOffset, votes.WorldwideResults)
+	votes.WorldwideResultsDetailed = make([]DetailedWorldwideResult, header.NumberOfDetailedWorldWideResults)
+	reader.read(header.DetailedWorldWideResultTableOffset, votes.WorldwideResultsDetailed)
+	votes.CountryInfoTable = make([]CountryInfoTable, header.NumberOfCountries)
+	reader.read(header.CountryTableOffset, votes.CountryInfoTable)
+
+	if len(votes.CountryInfoTable) != 0 {
+		// Country names run from the first name to the end of the file.
+		names := reader.bytes(votes.CountryInfoTable[0].TextOffset, uint32(len(data)))
+		votes.CountryTable = make([]uint16, len(names)/2)
+		reader.read(votes.CountryInfoTable[0].TextOffset, votes.CountryTable)
+	}
+
+	for _, info := range votes.CountryInfoTable {
+		votes.countryNames = append(votes.countryNames, decodeText(reader.text(info.TextOffset)))
+	}
+
+	if reader.err != nil {
+		return nil, reader.err
+	}
+
+	return votes, nil
+}
+
+// Text returns the question and responses of an entry in the QuestionTextInfoTable.
+func (v *Votes) Text(index int) (question string, response1 string, response2 string) {
+	text := v.QuestionText[index]
+	return decodeText(text.Question), decodeText(text.Response1), decodeText(text.Response2)
+}
+
+// CountryName returns the name of an entry in the CountryInfoTable of a decoded file.
+func (v *Votes) CountryName(index int) string {
+	if index < 0 || index >= len(v.countryNames) {
+		return ""
+	}
+
+	return v.countryNames[index]
+}
+
+// decodeText converts null terminated UTF-16.
+func decodeText(text []uint16) string {
+	if i := len(text) - 1; i >= 0 && text[i] == 0 {
+		text = text[:i]
+	}
+
+	return string(utf16.Decode(text))
+}
+
+var errTruncated = errors.New("file is truncated")
+
+// decoder reads tables at offsets within a file, stopping at the first error.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) read(offset uint32, value any) {
+	if d.err != nil {
+		return
+	}
+
+	if int(offset)+binary.Size(value) > len(d.data) {
+		d.err = fmt.Errorf("table at %d: %w", offset, errTruncated)
+		return
+	}
+
+	d.err = binary.Read(bytes.NewReader(d.data[offset:]), binary.BigEndian, value)
+}
+
+func (d *decoder) bytes(start uint32, end uint32) []byte {
+	if d.err != nil {
+		return nil
+	}
+
+	if start > end || int(end) > len(d.data) {
+		d.err = fmt.Errorf("table at %d: %w", start, errTruncated)
+		return nil
+	}
+
+	return d.data[start:end]
+}
+
+// text reads null terminated UTF-16, including the terminator.
+func (d *decoder) text(offset uint32) []uint16 {
+	var text []uint16
+	for d.err == nil {
+		var c uint16
+		d.read(offset, &c)
+		text = append(text, c)
+		offset += 2
+		if c == 0 {
+			break
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,58 @@
+package evc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	question := Question{
+		ID:           3,
+		QuestionText: LocalizedText{English: "Cats or dogs?"},
+		Response1:    LocalizedText{English: "Cats"},
+		Response2:    LocalizedText{English: "Dogs"},
+		Time:         time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	results := ResultSet{
+		National:         []NationalResult{{PollID: 1, MaleVotersResponse1: 4, FemaleVotersResponse2: 2, NationalResultDetailedNumber: 2}},
+		DetailedNational: [][]DetailedNationalResult{{{VotersResponse1Number: 4}, {VotersResponse2Number: 2}}},
+		Worldwide:        WorldWideResult{PollID: 2, MaleVotersResponse1: 7, NumberOfWorldWideDetailedTables: 1},
+		DetailedWorldwide: []DetailedWorldwideResult{
+			{MaleVotersResponse1: 7, CountryTableCount: 7, CountryTableNumber: 7},
+		},
+	}
+
+	builder := Builder{FileType: Normal, Locality: All, Time: question.Time}
+	votes, err := builder.MakeVotes(QuestionSet{National: []Question{question}}, results, 110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := votes.Encode()
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Writing a decoded file must give back the same bytes.
+	buffer := bytes.NewBuffer(nil)
+	decoded.WriteAll(buffer)
+	if !bytes.Equal(buffer.Bytes(), encoded) {
+		t.Errorf("decoded file does not encode to the original")
+	}
+
+	if text, response1, _ := decoded.Text(0); text != "Cats or dogs?" || response1 != "Cats" {
+		t.Errorf("unexpected question text %q and response %q", text, response1)
+	}
+
+	// Countries are sorted by code, so the second is Argentina, and English is its second name.
+	if name := decoded.CountryName(7 + 1); name != "Argentina" {
+		t.Errorf("expected Argentina, got %q", name)
+	}
+
+	if _, err = Decode(encoded[:len(encoded)-1]); err == nil {
+		t.Errorf("expected a truncated file to fail")
+	}
+}
@@ -0,0 +1,8 @@
+// Package evc builds the files downloaded by the Everybody Votes Channel.
+//
+// It has no knowledge of where questions and results are stored. Callers fill a
+// QuestionSet and ResultSet, then use a Builder to create signed voting files:
+//
+//	builder := evc.Builder{FileType: evc.Normal, Locality: evc.All, Key: key}
+//	data, err := builder.BuildVoting(questions, results, 49)
+package evc
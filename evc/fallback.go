@@ -0,0 +1,68 @@
+package evc
+
+import (
+	"slices"
+)
+
+// FallbackPolicy lists the languages to try, in order, when a question has no text in a file's language.
+// English is always tried last.
+type FallbackPolicy struct {
+	// Default applies to every country without its own chain for the language.
+	Default map[LanguageCode][]LanguageCode
+	// Countries overrides Default for a single country.
+	Countries map[uint8]map[LanguageCode][]LanguageCode
+}
+
+// DefaultFallbacks is used when no policy is configured.
+var DefaultFallbacks = FallbackPolicy{
+	Default: map[LanguageCode][]LanguageCode{
+		FrenchCanadian: {French},
+	},
+}
+
+// Fallback records a text that was written in another language.
+type Fallback struct {
+	QuestionID  int   `json:"question_id"`
+	CountryCode uint8 `json:"country_code"`
+	// Field is question, response1 or response2.
+	Field    string       `json:"field"`
+	Language LanguageCode `json:"language"`
+	Used     LanguageCode `json:"used"`
+}
+
+// Chain returns every language tried for the country and language, starting with the language itself.
+func (p *FallbackPolicy) Chain(countryCode uint8, language LanguageCode) []LanguageCode {
+	fallbacks, ok := p.Countries[countryCode][language]
+	if !ok {
+		fallbacks = p.Default[language]
+	}
+
+	chain := []LanguageCode{language}
+	for _, fallback := range fallbacks {
+		if !slices.Contains(chain, fallback) {
+			chain = append(chain, fallback)
+		}
+	}
+
+	if !slices.Contains(chain, English) {
+		chain = append(chain, English)
+	}
+
+	return chain
+}
+
+// Resolve returns the first translated text in the chain, and the language it is in.
+func (p *FallbackPolicy) Resolve(text LocalizedText, countryCode uint8, language LanguageCode) (string, LanguageCode) {
+	for _, candidate := range p.Chain(countryCode, language) {
+		if translated := text.Translation(candidate); translated != "" {
+			return translated, candidate
+		}
+	}
+
+	return "", language
+}
+
+// Fallbacks returns the texts written in another language by MakeVotes.
+func (v *Votes) Fallbacks() []Fallback {
+	return v.fallbacks
+}
@@ -0,0 +1,55 @@
+package evc
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFallbacks(t *testing.T) {
+	policy := &FallbackPolicy{
+		Default:   map[LanguageCode][]LanguageCode{FrenchCanadian: {French}},
+		Countries: map[uint8]map[LanguageCode][]LanguageCode{16: {Portuguese: {Spanish}}},
+	}
+
+	if chain := policy.Chain(18, FrenchCanadian); !slices.Equal(chain, []LanguageCode{FrenchCanadian, French, English}) {
+		t.Errorf("unexpected chain %v", chain)
+	}
+
+	// Only Brazil falls back to Spanish.
+	if chain := policy.Chain(18, Portuguese); !slices.Equal(chain, []LanguageCode{Portuguese, English}) {
+		t.Errorf("unexpected chain %v", chain)
+	}
+
+	question := Question{
+		ID:           4,
+		QuestionText: LocalizedText{English: "Cats or dogs?", French: "Chats ou chiens ?", Spanish: "¿Gatos o perros?"},
+		Response1:    LocalizedText{English: "Cats", Spanish: "Gatos", Portuguese: "Gatos"},
+		Response2:    LocalizedText{English: "Dogs", Portuguese: "Cães"},
+		Time:         time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Brazil supports English, Spanish, Portuguese and Canadian French.
+	builder := Builder{FileType: Questions, Locality: National, Time: question.Time, Fallbacks: policy}
+	votes, err := builder.MakeVotes(QuestionSet{National: []Question{question}}, ResultSet{}, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Fallback{
+		{QuestionID: 4, CountryCode: 16, Field: "response2", Language: Spanish, Used: English},
+		{QuestionID: 4, CountryCode: 16, Field: "question", Language: Portuguese, Used: Spanish},
+		{QuestionID: 4, CountryCode: 16, Field: "question", Language: FrenchCanadian, Used: French},
+		{QuestionID: 4, CountryCode: 16, Field: "response1", Language: FrenchCanadian, Used: English},
+		{QuestionID: 4, CountryCode: 16, Field: "response2", Language: FrenchCanadian, Used: English},
+	}
+
+	if !slices.Equal(votes.Fallbacks(), expected) {
+		t.Errorf("unexpected fallbacks %+v", votes.Fallbacks())
+	}
+
+	// Responses must never fall back to the question.
+	if _, _, response2 := votes.Text(3); response2 != "Dogs" {
+		t.Errorf("expected the English response, got %q", response2)
+	}
+}
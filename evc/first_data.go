@@ -1,7 +1,8 @@
-package main
+package evc
 
 import (
 	"bytes"
+	"crypto/rsa"
 	"encoding/binary"
 	"github.com/wii-tools/lz11"
 	"hash/crc32"
@@ -27,26 +28,27 @@ type CountryTable struct {
 	SupportedLanguages         [4]LanguageCode
 }
 
-func MakeFirstData() []byte {
+// BuildFirstData creates the signed first_data.bin listing every country and its languages.
+func BuildFirstData(key *rsa.PrivateKey) ([]byte, error) {
 	buffer := new(bytes.Buffer)
 
 	data := FirstData{
 		Version:            1,
 		Filesize:           0,
 		CRC32:              0,
-		NumberOfCountries:  uint8(len(countryCodes)),
+		NumberOfCountries:  uint8(len(CountryCodes)),
 		CountryTableOffset: uint32(18 + len(supportedLanguages)*4),
 		NumberOfLanguages:  uint8(len(supportedLanguages)),
 		LanguageTable:      make([]uint32, len(supportedLanguages)),
 	}
 
-	for _, code := range countryCodes {
+	for _, code := range CountryCodes {
 		var languageCodes [4]LanguageCode
-		copy(languageCodes[:], countriesSupportedLanguages[code])
+		copy(languageCodes[:], CountriesSupportedLanguages[code])
 
 		data.CountryTable = append(data.CountryTable, CountryTable{
 			CountryCode:                code,
-			NumberOfSupportedLanguages: uint8(len(countriesSupportedLanguages[code])),
+			NumberOfSupportedLanguages: uint8(len(CountriesSupportedLanguages[code])),
 			SupportedLanguages:         languageCodes,
 		})
 	}
@@ -69,11 +71,11 @@ func MakeFirstData() []byte {
 	data.WriteAll(buffer)
 
 	compressed, err := lz11.Compress(buffer.Bytes())
-	checkError(err)
-
-	signed := SignFile(compressed)
+	if err != nil {
+		return nil, err
+	}
 
-	return signed
+	return SignFile(key, compressed)
 }
 
 // Write writes the current values in Votes to an io.Writer method.
@@ -81,7 +83,9 @@ func MakeFirstData() []byte {
 // but can write them individually.
 func (f *FirstData) Write(writer io.Writer, data interface{}) {
 	err := binary.Write(writer, binary.BigEndian, data)
-	checkError(err)
+	if err != nil {
+		panic(err)
+	}
 }
 
 func (f *FirstData) WriteAll(writer io.Writer) {
@@ -1,6 +1,4 @@
-package main
-
-import "time"
+package evc
 
 type Header struct {
 	Version                            uint32
@@ -34,7 +32,7 @@ type Header struct {
 func (v *Votes) MakeHeader() {
 	questionVersion := 1
 	resultVersion := 0
-	if fileType == Results {
+	if v.builder.FileType == Results {
 		questionVersion = 0
 		resultVersion = 1
 	}
@@ -43,7 +41,7 @@ func (v *Votes) MakeHeader() {
 		Version:                            0,
 		Filesize:                           0,
 		CRC32:                              0,
-		Timestamp:                          GenerateCurrentTimestamp(),
+		Timestamp:                          CreateTimestamp(int(v.builder.Time.Unix())),
 		CountryCode:                        v.currentCountryCode,
 		PublicityFlag:                      0,
 		QuestionVersion:                    uint8(questionVersion),
@@ -69,10 +67,6 @@ func (v *Votes) MakeHeader() {
 	}
 }
 
-func GenerateCurrentTimestamp() uint32 {
-	return CreateTimestamp(int(time.Now().Unix()))
-}
-
 func CreateTimestamp(time int) uint32 {
 	return uint32((time - 946684800) / 60)
 }
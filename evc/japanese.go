@@ -0,0 +1,61 @@
+package evc
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CheckJapanese clears Japanese text that cannot be shown, so those fields fall back to English,
+// and returns why each was cleared.
+func (q *Question) CheckJapanese() []error {
+	var errs []error
+	for _, field := range []struct {
+		name     string
+		text     *string
+		isScript bool
+	}{
+		{"question", &q.QuestionText.Japanese, true},
+		// Responses such as "OK" or "iPhone" are fine without any Japanese script.
+		{"response 1", &q.Response1.Japanese, false},
+		{"response 2", &q.Response2.Japanese, false},
+	} {
+		if *field.text == "" {
+			continue
+		}
+
+		if err := validateJapanese(*field.text, field.isScript); err != nil {
+			errs = append(errs, fmt.Errorf("Japanese %s: %w", field.name, err))
+			*field.text = ""
+		}
+	}
+
+	return errs
+}
+
+func validateJapanese(text string, isScript bool) error {
+	if !utf8.ValidString(text) {
+		return errors.New("text is not valid UTF-8")
+	}
+
+	hasScript := false
+	for _, r := range text {
+		switch {
+		case r > 0xffff:
+			// Text is written as UTF-16 and the channel cannot draw surrogate pairs.
+			return fmt.Errorf("%q is outside the Basic Multilingual Plane", r)
+		case unicode.IsControl(r) && r != '\n':
+			return fmt.Errorf("contains the control character %U", r)
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han):
+			hasScript = true
+		}
+	}
+
+	if isScript && !hasScript {
+		// Usually English pasted into the Japanese column.
+		return errors.New("text contains no Japanese")
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package evc
+
+import (
+	"testing"
+)
+
+func TestJapaneseFallback(t *testing.T) {
+	question := Question{
+		QuestionText: LocalizedText{English: "Cats or dogs?", Japanese: "ネコとイヌ、どっちが好き？"},
+		Response1:    LocalizedText{English: "Cats", Japanese: "ネコ"},
+		Response2:    LocalizedText{English: "Dogs"},
+	}
+
+	if text := question.QuestionText.Get(Japanese); text != "ネコとイヌ、どっちが好き？" {
+		t.Errorf("expected the Japanese question, got %q", text)
+	}
+
+	// Untranslated fields fall back to English on their own.
+	if text := question.Response2.Get(Japanese); text != "Dogs" {
+		t.Errorf("expected the English response, got %q", text)
+	}
+
+	question.QuestionText.Japanese = "Cats or dogs?"
+	question.Response1.Japanese = "ネコ🐈"
+	if errs := question.CheckJapanese(); len(errs) != 2 {
+		t.Errorf("expected English text and an emoji to be rejected, got %v", errs)
+	}
+
+	if question.QuestionText.Get(Japanese) != "Cats or dogs?" || question.Response1.Get(Japanese) != "Cats" {
+		t.Errorf("rejected fields should fall back to English")
+	}
+}
@@ -0,0 +1,135 @@
+package evc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Layout is the space a field has on screen.
+type Layout struct {
+	// Width is measured in half width characters, so a line fits half as many full width characters.
+	Width    int
+	MaxLines int
+}
+
+// Layouts are the spaces for a question's text and its responses.
+type Layouts struct {
+	Question Layout
+	Response Layout
+}
+
+// DefaultLayouts are used unless config.xml sets its own.
+// The question width of 50 is what questions were wrapped at with go-wordwrap before layouts were added,
+// and published questions fit at that width. The line limits and the response width have not been
+// measured against the channel's text boxes, so they are provisional and should be replaced in config.xml
+// once they have been.
+var DefaultLayouts = Layouts{
+	Question: Layout{Width: 50, MaxLines: 4},
+	Response: Layout{Width: 24, MaxLines: 2},
+}
+
+// ErrTextTooLong is returned when text cannot fit in its layout, as the channel would clip it.
+var ErrTextTooLong = errors.New("text does not fit")
+
+// Wrap breaks text into lines that fit the layout. Existing line breaks are kept.
+// Lines break at spaces or between full width characters, but never before closing punctuation
+// or after an opening bracket, and never within a word.
+func (l Layout) Wrap(text string) (string, error) {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line []rune
+		for _, r := range paragraph {
+			if lineWidth(line)+runeWidth(r) > l.Width && len(strings.TrimSpace(string(line))) != 0 {
+				split := len(line)
+				next := r
+				for split > 0 && !canBreak(line[split-1], next) {
+					split--
+					next = line[split]
+				}
+
+				if split == 0 {
+					word := string(line) + string(r)
+					if i := strings.IndexFunc(word, unicode.IsSpace); i != -1 {
+						word = word[:i]
+					}
+
+					return "", fmt.Errorf("%w: %q is wider than a line of %d", ErrTextTooLong, word, l.Width)
+				}
+
+				lines = append(lines, strings.TrimRightFunc(string(line[:split]), unicode.IsSpace))
+				line = []rune(strings.TrimLeftFunc(string(line[split:]), unicode.IsSpace))
+			}
+
+			// Spaces are dropped from the start of every line.
+			if len(line) == 0 && unicode.IsSpace(r) {
+				continue
+			}
+
+			line = append(line, r)
+
+			// The text carried over from a break may still not fit with r.
+			if width := lineWidth([]rune(strings.TrimRightFunc(string(line), unicode.IsSpace))); width > l.Width {
+				return "", fmt.Errorf("%w: %q cannot be broken to fit a line of %d", ErrTextTooLong, string(line), l.Width)
+			}
+		}
+
+		lines = append(lines, strings.TrimRightFunc(string(line), unicode.IsSpace))
+	}
+
+	if len(lines) > l.MaxLines {
+		return "", fmt.Errorf("%w: %d lines, at most %d", ErrTextTooLong, len(lines), l.MaxLines)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+const (
+	// noLineStart are characters which may not start a line.
+	noLineStart = "、。，．・：；？！ー～）」』】〕〉》’”ぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ々ゝゞヽヾ,.:;?!)]}"
+	// noLineEnd are characters which may not end a line.
+	noLineEnd = "（「『【〔〈《‘“([{"
+)
+
+// canBreak reports whether a line may break between before and after.
+func canBreak(before rune, after rune) bool {
+	if strings.ContainsRune(noLineStart, after) || strings.ContainsRune(noLineEnd, before) {
+		return false
+	}
+
+	if unicode.IsSpace(before) || unicode.IsSpace(after) {
+		return true
+	}
+
+	// Full width text has no spaces between words.
+	return runeWidth(before) == 2 || runeWidth(after) == 2
+}
+
+func lineWidth(line []rune) int {
+	width := 0
+	for _, r := range line {
+		width += runeWidth(r)
+	}
+
+	return width
+}
+
+// runeWidth returns the number of half width columns a character takes up.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.Is(unicode.Mn, r):
+		// Combining marks are drawn over the previous character.
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6:
+		return 2
+	}
+
+	return 1
+}
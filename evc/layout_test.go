@@ -0,0 +1,92 @@
+package evc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	for _, test := range []struct {
+		text  string
+		width int
+		lines []string
+	}{
+		{"Do you prefer cats or dogs?", 12, []string{"Do you", "prefer cats", "or dogs?"}},
+		{"Cats  or dogs", 5, []string{"Cats", "or", "dogs"}},
+		// Each full width character takes two columns.
+		{"あいうえおかきくけこ", 10, []string{"あいうえお", "かきくけこ"}},
+		// Closing punctuation moves to the next line with the character before it.
+		{"あいうえ。かきく", 8, []string{"あいう", "え。かき", "く"}},
+		// Opening brackets move to the next line.
+		{"あいう「えお」", 8, []string{"あいう", "「えお」"}},
+		// Latin words are not split.
+		{"あいうWii", 8, []string{"あいう", "Wii"}},
+		{"あい\nうえ", 50, []string{"あい", "うえ"}},
+	} {
+		wrapped, err := Layout{Width: test.width, MaxLines: 10}.Wrap(test.text)
+		if err != nil {
+			t.Errorf("%q: %v", test.text, err)
+			continue
+		}
+
+		if lines := strings.Split(wrapped, "\n"); strings.Join(lines, "|") != strings.Join(test.lines, "|") {
+			t.Errorf("%q: got %q, expected %q", test.text, lines, test.lines)
+		}
+	}
+
+	if _, err := (Layout{Width: 8, MaxLines: 2}).Wrap("one two three four"); !errors.Is(err, ErrTextTooLong) {
+		t.Errorf("expected too many lines to fail, got %v", err)
+	}
+
+	if _, err := (Layout{Width: 8, MaxLines: 2}).Wrap("an extraordinary"); !errors.Is(err, ErrTextTooLong) {
+		t.Errorf("expected a word wider than a line to fail, got %v", err)
+	}
+
+	// Text carried over from a break must still fit, or fail.
+	for _, test := range []struct {
+		text  string
+		width int
+	}{
+		{"aあ、。」」」", 11},
+		{"い,b、!」a", 8},
+		{"「,,,W」、。い", 11},
+	} {
+		wrapped, err := Layout{Width: test.width, MaxLines: 10}.Wrap(test.text)
+		if !errors.Is(err, ErrTextTooLong) {
+			t.Errorf("%q: expected a run that cannot be broken to fail, got %q", test.text, wrapped)
+		}
+	}
+
+	// Leading spaces are dropped rather than pushing the first word past the width.
+	if wrapped, err := (Layout{Width: 4, MaxLines: 2}).Wrap("    Wii"); err != nil || wrapped != "Wii" {
+		t.Errorf("got %q, %v", wrapped, err)
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	question := Question{
+		ID:           5,
+		QuestionText: LocalizedText{English: strings.Repeat("Cats or dogs? ", 5)},
+		Response1:    LocalizedText{English: "Cats", German: strings.Repeat("Katzen ", 10)},
+	}
+
+	dropped, err := question.SanitizeText(DefaultLayouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The German response falls back rather than failing every language.
+	if len(dropped) != 1 || !strings.Contains(dropped[0].Error(), "question 5 de response 1") || question.Response1.German != "" {
+		t.Errorf("expected the German response to be dropped, got %v", dropped)
+	}
+
+	if question.QuestionText.English != "Cats or dogs? Cats or dogs? Cats or dogs? Cats or\ndogs? Cats or dogs?" {
+		t.Errorf("unexpected wrapped question %q", question.QuestionText.English)
+	}
+
+	question.Response2.English = strings.Repeat("Dogs ", 10)
+	if _, err = question.SanitizeText(DefaultLayouts); err == nil || !strings.Contains(err.Error(), "question 5 en response 2") {
+		t.Errorf("expected English that does not fit to fail, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package evc
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidLZ11 = errors.New("invalid LZ11 data")
+
+// Decompress decompresses LZ11 data, as produced by lz11.Compress.
+// The decompressor in that package cannot be used from multiple goroutines.
+func Decompress(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0x11 {
+		return nil, ErrInvalidLZ11
+	}
+
+	size := int(data[1]) | int(data[2])<<8 | int(data[3])<<16
+	pos := 4
+	if size == 0 {
+		// Sizes that do not fit in 24 bits follow the header.
+		if len(data) < 8 {
+			return nil, ErrInvalidLZ11
+		}
+
+		size = int(binary.LittleEndian.Uint32(data[4:]))
+		pos = 8
+	}
+
+	out := make([]byte, 0, size)
+	for len(out) < size {
+		if pos >= len(data) {
+			return nil, ErrInvalidLZ11
+		}
+
+		flags := data[pos]
+		pos++
+
+		for bit := 7; bit >= 0 && len(out) < size; bit-- {
+			if flags&(1<<bit) == 0 {
+				// Copy a byte as-is.
+				if pos >= len(data) {
+					return nil, ErrInvalidLZ11
+				}
+
+				out = append(out, data[pos])
+				pos++
+				continue
+			}
+
+			if pos+1 >= len(data) {
+				return nil, ErrInvalidLZ11
+			}
+
+			var count, disp int
+			switch indicator := data[pos] >> 4; indicator {
+			case 0:
+				// 8 bit count, 12 bit disp
+				if pos+2 >= len(data) {
+					return nil, ErrInvalidLZ11
+				}
+
+				count = (int(data[pos]&0xf)<<4 | int(data[pos+1])>>4) + 0x11
+				disp = int(data[pos+1]&0xf)<<8 | int(data[pos+2])
+				pos += 3
+			case 1:
+				// 16 bit count, 12 bit disp
+				if pos+3 >= len(data) {
+					return nil, ErrInvalidLZ11
+				}
+
+				count = (int(data[pos]&0xf)<<12 | int(data[pos+1])<<4 | int(data[pos+2])>>4) + 0x111
+				disp = int(data[pos+2]&0xf)<<8 | int(data[pos+3])
+				pos += 4
+			default:
+				// Indicator is count, 12 bit disp
+				count = int(indicator) + 1
+				disp = int(data[pos]&0xf)<<8 | int(data[pos+1])
+				pos += 2
+			}
+
+			disp++
+			if disp > len(out) {
+				return nil, ErrInvalidLZ11
+			}
+
+			for ; count > 0 && len(out) < size; count-- {
+				out = append(out, out[len(out)-disp])
+			}
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,32 @@
+package evc
+
+import (
+	"bytes"
+	"github.com/wii-tools/lz11"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	// Long runs exercise the 8 and 16 bit count encodings.
+	data := bytes.Repeat([]byte("Everybody Votes Channel "), 40)
+	data = append(data, make([]byte, 5000)...)
+	data = append(data, []byte("end of file")...)
+
+	compressed, err := lz11.Compress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decompressed, err := Decompress(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, decompressed) {
+		t.Errorf("decompressed data does not match the original")
+	}
+
+	if _, err = Decompress(compressed[:len(compressed)/2]); err == nil {
+		t.Errorf("expected truncated data to fail")
+	}
+}
@@ -1,7 +1,7 @@
-package main
+package evc
 
 import (
-	"encoding/hex"
+	"fmt"
 )
 
 // QuestionInfo contains metadata for both national and worldwide questions.
@@ -51,18 +51,18 @@ func (v *Votes) MakeNationalQuestionsTable() {
 	v.Header.NationalQuestionTableOffset = v.GetCurrentSize()
 	entryNum := 0
 
-	for _, question := range nationalQuestions {
+	for _, question := range v.questions.National {
 		v.NationalQuestionTable = append(v.NationalQuestionTable, QuestionInfo{
 			PollID:                     uint32(question.ID),
 			PollCategory1:              uint8(question.Category),
-			PollCategory2:              categoryKV[question.Category],
+			PollCategory2:              CategoryKV[question.Category],
 			StartingTimestamp:          CreateTimestamp(int(question.Time.Unix())),
 			EndingTimestamp:            CreateTimestamp(int(question.Time.Unix())) + 10080,
-			NumberOfSupportedLanguages: uint8(len(countriesSupportedLanguages[v.currentCountryCode])),
+			NumberOfSupportedLanguages: uint8(len(CountriesSupportedLanguages[v.currentCountryCode])),
 			QuestionTableEntryNumber:   uint32(entryNum),
 		})
 
-		entryNum += len(countriesSupportedLanguages[v.currentCountryCode])
+		entryNum += len(CountriesSupportedLanguages[v.currentCountryCode])
 	}
 
 	v.Header.NumberOfNationalQuestions = uint8(len(v.NationalQuestionTable))
@@ -70,12 +70,9 @@ func (v *Votes) MakeNationalQuestionsTable() {
 
 // MakeNationalResultsTable creates the results for the past six (6) national questions.
 func (v *Votes) MakeNationalResultsTable() {
-	result, detailed := v.PrepareNationalResults()
-	v.tempDetailedResults = detailed
-
-	if result != nil {
+	if v.results.National != nil {
 		v.Header.NationalResultTableOffset = v.GetCurrentSize()
-		v.NationalResults = append(v.NationalResults, result...)
+		v.NationalResults = append(v.NationalResults, v.results.National...)
 	}
 
 	v.Header.NumberOfNationalResults = uint8(len(v.NationalResults))
@@ -85,24 +82,31 @@ func (v *Votes) MakeNationalResultsTable() {
 func (v *Votes) MakeDetailedNationalResultsTable() {
 	v.Header.DetailedNationalResultTableOffset = v.GetCurrentSize()
 
-	for _, result := range v.tempDetailedResults {
+	for _, result := range v.results.DetailedNational {
 		v.DetailedNationalResults = append(v.DetailedNationalResults, result...)
 	}
 
 	v.Header.NumberOfDetailedNationalResults = uint16(len(v.DetailedNationalResults))
 }
 
-// MakePositionTable creates the position table for the current country.
-func (v *Votes) MakePositionTable() {
-	for i, str := range positionData {
-		if uint8(i) == v.currentCountryCode {
-			v.Header.PositionTableOffset = v.GetCurrentSize()
-			v.Header.NumberOfPositionTables = uint16(numberOfRegions[v.currentCountryCode])
+// MakePositionTable creates the position table for the current country from its region points.
+// Countries without position data have no table, and data which does not match its PositionTable entry is an error.
+func (v *Votes) MakePositionTable() error {
+	if _, ok := PositionData[int(v.currentCountryCode)]; !ok {
+		return nil
+	}
 
-			position, err := hex.DecodeString(str)
-			checkError(err)
+	regions, err := RegionPoints(v.currentCountryCode)
+	if err != nil {
+		return fmt.Errorf("country %d position data: %w", v.currentCountryCode, err)
+	}
 
-			v.PositionEntryTable = position
-		}
+	if err = regions.Validate(v.currentCountryCode); err != nil {
+		return err
 	}
+
+	v.Header.PositionTableOffset = v.GetCurrentSize()
+	v.Header.NumberOfPositionTables = uint16(NumberOfRegions[v.currentCountryCode])
+	v.PositionEntryTable = regions.Bytes()
+	return nil
 }
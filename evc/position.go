@@ -0,0 +1,108 @@
+package evc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Point is a position on a country's results map.
+type Point struct {
+	X uint8 `json:"x"`
+	Y uint8 `json:"y"`
+}
+
+// PositionMap is the points of each region of a country, in the order of their region IDs.
+// Region IDs start at 2, so the points of region 2 are at index 0.
+type PositionMap [][]Point
+
+// RegionPoints decodes a country's PositionData into the points of each region,
+// using PositionTable for the number of points each region has.
+func RegionPoints(countryCode uint8) (PositionMap, error) {
+	data, ok := PositionData[int(countryCode)]
+	if !ok {
+		return nil, fmt.Errorf("country %d has no position data", countryCode)
+	}
+
+	counts, ok := PositionTable[countryCode]
+	if !ok {
+		return nil, fmt.Errorf("country %d has no position table", countryCode)
+	}
+
+	return DecodePositionMap(counts, data)
+}
+
+// DecodePositionMap decodes hex encoded position data, where each point is an X then a Y byte,
+// and counts is the number of points in each region.
+func DecodePositionMap(counts []uint8, data string) (PositionMap, error) {
+	position, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if expected := sum(counts) * 2; len(position) != expected {
+		return nil, fmt.Errorf("%d bytes of position data, but the position table requires %d", len(position), expected)
+	}
+
+	regions := make(PositionMap, len(counts))
+	for i, count := range counts {
+		for j := 0; j < int(count); j++ {
+			regions[i] = append(regions[i], Point{X: position[0], Y: position[1]})
+			position = position[2:]
+		}
+	}
+
+	return regions, nil
+}
+
+// Validate checks that the map has an entry for every region of the country.
+func (m PositionMap) Validate(countryCode uint8) error {
+	regions, ok := NumberOfRegions[countryCode]
+	if !ok {
+		return fmt.Errorf("country %d is not supported", countryCode)
+	}
+
+	if len(m) != int(regions) {
+		return fmt.Errorf("country %d has %d regions, but the map has %d", countryCode, regions, len(m))
+	}
+
+	for i, points := range m {
+		// Regions may have no points, such as those too small to show on the map.
+		if len(points) > 255 {
+			return fmt.Errorf("region %d has %d points, but at most 255 are supported", i+2, len(points))
+		}
+	}
+
+	return nil
+}
+
+// Encode returns the map as a PositionTable entry and hex encoded PositionData.
+func (m PositionMap) Encode() ([]uint8, string) {
+	counts := make([]uint8, len(m))
+	for i, points := range m {
+		counts[i] = uint8(len(points))
+	}
+
+	return counts, strings.ToUpper(hex.EncodeToString(m.Bytes()))
+}
+
+// Bytes returns the points of every region in order, as written to the position table of a file.
+func (m PositionMap) Bytes() []byte {
+	var data []byte
+	for _, points := range m {
+		for _, point := range points {
+			data = append(data, point.X, point.Y)
+		}
+	}
+
+	return data
+}
+
+func sum(counts []uint8) int {
+	total := 0
+	for _, count := range counts {
+		total += int(count)
+	}
+
+	return total
+}
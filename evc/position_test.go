@@ -0,0 +1,31 @@
+package evc
+
+import (
+	"testing"
+)
+
+func TestPositionMap(t *testing.T) {
+	for countryCode, data := range PositionData {
+		regions, err := RegionPoints(uint8(countryCode))
+		if err != nil {
+			t.Fatalf("country %d: %v", countryCode, err)
+		}
+
+		if err = regions.Validate(uint8(countryCode)); err != nil {
+			t.Errorf("country %d: %v", countryCode, err)
+		}
+
+		counts, encoded := regions.Encode()
+		if encoded != data || string(counts) != string(PositionTable[uint8(countryCode)]) {
+			t.Errorf("country %d does not encode to its original data", countryCode)
+		}
+	}
+
+	if _, err := DecodePositionMap([]uint8{1, 1}, "0102"); err == nil {
+		t.Errorf("expected position data with fewer points than its table to be invalid")
+	}
+
+	if err := (PositionMap{{{1, 2}}}).Validate(110); err == nil {
+		t.Errorf("expected a map with too few regions to be invalid")
+	}
+}
@@ -0,0 +1,96 @@
+package evc
+
+// ApplyNationalThreshold hides the parts of a national result with fewer than minimum voters.
+// Regions below the threshold are zeroed. If the zeroed regions together still have fewer than
+// minimum voters, they could be recovered by subtracting the others from the total,
+// so the smallest remaining regions are zeroed with them until the group reaches the threshold.
+// If the whole country is below the threshold, the voter numbers and detailed results are not shown.
+func ApplyNationalThreshold(result *NationalResult, regions []DetailedNationalResult, minimum uint32) {
+	if minimum == 0 {
+		return
+	}
+
+	total := result.MaleVotersResponse1 + result.MaleVotersResponse2 + result.FemaleVotersResponse1 + result.FemaleVotersResponse2
+	if total < minimum {
+		result.ShowVoterNumberFlag = 0
+		result.ShowDetailedResultsFlag = 0
+		for i := range regions {
+			regions[i].VotersResponse1Number = 0
+			regions[i].VotersResponse2Number = 0
+		}
+
+		return
+	}
+
+	totals := make([]uint32, len(regions))
+	for i, region := range regions {
+		totals[i] = region.VotersResponse1Number + region.VotersResponse2Number
+	}
+
+	visible := false
+	for i, hidden := range hideBelow(totals, minimum) {
+		if hidden {
+			regions[i].VotersResponse1Number = 0
+			regions[i].VotersResponse2Number = 0
+		} else if totals[i] != 0 {
+			visible = true
+		}
+	}
+
+	if !visible {
+		result.ShowDetailedResultsFlag = 0
+	}
+}
+
+// ApplyWorldwideThreshold zeroes the countries of a worldwide result with fewer than minimum voters,
+// following the same rules as regions in ApplyNationalThreshold.
+func ApplyWorldwideThreshold(countries []DetailedWorldwideResult, minimum uint32) {
+	if minimum == 0 {
+		return
+	}
+
+	totals := make([]uint32, len(countries))
+	for i, country := range countries {
+		totals[i] = country.MaleVotersResponse1 + country.MaleVotersResponse2 + country.FemaleVotersResponse1 + country.FemaleVotersResponse2
+	}
+
+	for i, hidden := range hideBelow(totals, minimum) {
+		if hidden {
+			countries[i].MaleVotersResponse1 = 0
+			countries[i].MaleVotersResponse2 = 0
+			countries[i].FemaleVotersResponse1 = 0
+			countries[i].FemaleVotersResponse2 = 0
+		}
+	}
+}
+
+// hideBelow returns which totals to hide so that no total, nor the sum of the hidden totals, is
+// below minimum unless it is zero.
+func hideBelow(totals []uint32, minimum uint32) []bool {
+	hidden := make([]bool, len(totals))
+	var hiddenSum uint32
+	for i, total := range totals {
+		if total != 0 && total < minimum {
+			hidden[i] = true
+			hiddenSum += total
+		}
+	}
+
+	for hiddenSum != 0 && hiddenSum < minimum {
+		smallest := -1
+		for i, total := range totals {
+			if !hidden[i] && total != 0 && (smallest == -1 || total < totals[smallest]) {
+				smallest = i
+			}
+		}
+
+		if smallest == -1 {
+			break
+		}
+
+		hidden[smallest] = true
+		hiddenSum += totals[smallest]
+	}
+
+	return hidden
+}
@@ -0,0 +1,50 @@
+package evc
+
+import (
+	"testing"
+)
+
+func TestApplyNationalThreshold(t *testing.T) {
+	result := NationalResult{MaleVotersResponse1: 20, FemaleVotersResponse2: 2, ShowVoterNumberFlag: 1, ShowDetailedResultsFlag: 1}
+	regions := []DetailedNationalResult{
+		{VotersResponse1Number: 10},
+		{VotersResponse1Number: 6},
+		{VotersResponse1Number: 4, VotersResponse2Number: 1},
+		{VotersResponse2Number: 1},
+		{},
+	}
+
+	ApplyNationalThreshold(&result, regions, 5)
+
+	// The region with one voter is hidden along with the smallest region, otherwise it could be recovered from the total.
+	expected := []uint32{10, 6, 0, 0, 0}
+	for i, region := range regions {
+		if region.VotersResponse1Number+region.VotersResponse2Number != expected[i] {
+			t.Errorf("region %d: expected %d voters, got %+v", i, expected[i], region)
+		}
+	}
+
+	if result.ShowVoterNumberFlag != 1 || result.ShowDetailedResultsFlag != 1 {
+		t.Errorf("flags should remain set above the threshold: %+v", result)
+	}
+
+	small := NationalResult{MaleVotersResponse1: 2, ShowVoterNumberFlag: 1, ShowDetailedResultsFlag: 1}
+	regions = []DetailedNationalResult{{VotersResponse1Number: 2}}
+	ApplyNationalThreshold(&small, regions, 5)
+	if small.ShowVoterNumberFlag != 0 || small.ShowDetailedResultsFlag != 0 || regions[0].VotersResponse1Number != 0 {
+		t.Errorf("a country below the threshold should be hidden: %+v, %+v", small, regions)
+	}
+}
+
+func TestApplyWorldwideThreshold(t *testing.T) {
+	countries := []DetailedWorldwideResult{
+		{MaleVotersResponse1: 100},
+		{FemaleVotersResponse2: 3},
+		{MaleVotersResponse2: 4},
+	}
+
+	ApplyWorldwideThreshold(countries, 5)
+	if countries[0].MaleVotersResponse1 != 100 || countries[1].FemaleVotersResponse2 != 0 || countries[2].MaleVotersResponse2 != 0 {
+		t.Errorf("unexpected result: %+v", countries)
+	}
+}
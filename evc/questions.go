@@ -1,4 +1,4 @@
-package main
+package evc
 
 import (
 	"unicode/utf16"
@@ -24,7 +24,7 @@ func (v *Votes) MakeQuestionsTable() {
 	v.Header.QuestionTextInfoTableOffset = v.GetCurrentSize()
 
 	// Get all the questions for the current country.
-	for _, _ = range append(append([]Question{}, nationalQuestions...), worldwideQuestion) {
+	for _, _ = range append(append([]Question{}, v.questions.National...), v.questions.Worldwide) {
 		for _, language := range GetSupportedLanguages(v.currentCountryCode) {
 			v.QuestionTextInfoTable = append(v.QuestionTextInfoTable, QuestionTextInfo{
 				LanguageCode:    uint8(language),
@@ -37,7 +37,7 @@ func (v *Votes) MakeQuestionsTable() {
 
 	// Now the text
 	index := 0
-	for _, question := range append(append([]Question{}, nationalQuestions...), worldwideQuestion) {
+	for _, question := range append(append([]Question{}, v.questions.National...), v.questions.Worldwide) {
 		for _, language := range GetSupportedLanguages(v.currentCountryCode) {
 			v.QuestionTextInfoTable[index].QuestionOffset = v.GetCurrentSize()
 
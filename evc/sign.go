@@ -0,0 +1,165 @@
+package evc
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+)
+
+// LoadPrivateKey reads the PKCS #8 RSA key used to sign every file.
+func LoadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	rsaData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaBlock, _ := pem.Decode(rsaData)
+	if rsaBlock == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+
+	parsedKey, err := x509.ParsePKCS8PrivateKey(rsaBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := parsedKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an RSA key", path)
+	}
+
+	return key, nil
+}
+
+// LoadPublicKey reads an RSA public key to verify files with. A private key may also be passed.
+func LoadPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+
+	var parsedKey any
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsedKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsedKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s contains an unsupported %s", path, block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsedKey.(type) {
+	case *rsa.PublicKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return &key.PublicKey, nil
+	}
+
+	return nil, fmt.Errorf("%s is not an RSA key", path)
+}
+
+// SignFile prepends the RSA signature of contents, as required by the channel.
+func SignFile(key *rsa.PrivateKey, contents []byte) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+
+	// Hash our data then sign
+	hash := sha1.New()
+	_, err := hash.Write(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	contentsHashSum := hash.Sum(nil)
+
+	reader := rand.Reader
+	signature, err := rsa.SignPKCS1v15(reader, key, crypto.SHA1, contentsHashSum)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer.Write(make([]byte, 64))
+	buffer.Write(signature)
+	buffer.Write(contents)
+
+	return buffer.Bytes(), nil
+}
+
+// Unpack verifies the signature of a file created by SignFile,
+// then decompresses and checks the size and CRC32 of its contents.
+func Unpack(data []byte, key *rsa.PublicKey) ([]byte, error) {
+	if len(data) < 64+key.Size() {
+		return nil, errors.New("file is smaller than its signature")
+	}
+
+	signature := data[64 : 64+key.Size()]
+	contents := data[64+key.Size():]
+
+	hash := sha1.Sum(contents)
+	err := rsa.VerifyPKCS1v15(key, crypto.SHA1, hash[:], signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return unpackContents(contents)
+}
+
+// UnpackUnverified unpacks a file created by SignFile without checking its signature, for when the key is not at hand.
+// The length of the signature depends on the key, so each common length is tried until the contents check out.
+func UnpackUnverified(data []byte) ([]byte, error) {
+	err := errors.New("file is smaller than its signature")
+	for _, size := range []int{128, 256, 512} {
+		if len(data) < 64+size {
+			break
+		}
+
+		var decompressed []byte
+		if decompressed, err = unpackContents(data[64+size:]); err == nil {
+			return decompressed, nil
+		}
+	}
+
+	return nil, err
+}
+
+// unpackContents decompresses signed contents, then checks their size and CRC32.
+func unpackContents(contents []byte) ([]byte, error) {
+	decompressed, err := Decompress(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both voting files and first_data.bin start with a version, file size and CRC32.
+	if len(decompressed) < 12 {
+		return nil, errors.New("file is too small to contain a header")
+	}
+
+	if size := binary.BigEndian.Uint32(decompressed[4:]); size != uint32(len(decompressed)) {
+		return nil, fmt.Errorf("header file size %d does not match actual size %d", size, len(decompressed))
+	}
+
+	if checksum := binary.BigEndian.Uint32(decompressed[8:]); checksum != crc32.ChecksumIEEE(decompressed[12:]) {
+		return nil, errors.New("CRC32 does not match contents")
+	}
+
+	return decompressed, nil
+}
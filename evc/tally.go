@@ -0,0 +1,88 @@
+package evc
+
+import (
+	"fmt"
+)
+
+// Tally is the number of votes and predictions for each response.
+type Tally struct {
+	MaleResponse1       uint32
+	MaleResponse2       uint32
+	FemaleResponse1     uint32
+	FemaleResponse2     uint32
+	PredictorsResponse1 uint32
+	PredictorsResponse2 uint32
+}
+
+// TallyFromAnsCnt converts a row using the legacy ans_cnt encoding, where each decimal digit is a count of
+// male votes for response 1, female votes for response 1, male votes for response 2 and female votes for
+// response 2 in that order, with leading zeros omitted. Predictions use the same digits regardless of gender.
+// As each count is a single digit, values which do not fit in 4 digits are rejected rather than truncated.
+func TallyFromAnsCnt(voteType VoteType, ansCnt int64) (Tally, error) {
+	if ansCnt < 0 || ansCnt > 9999 {
+		return Tally{}, fmt.Errorf("ans_cnt %d is not 4 decimal digits", ansCnt)
+	}
+
+	male1 := uint32(ansCnt / 1000 % 10)
+	female1 := uint32(ansCnt / 100 % 10)
+	male2 := uint32(ansCnt / 10 % 10)
+	female2 := uint32(ansCnt % 10)
+
+	if voteType == Prediction {
+		return Tally{PredictorsResponse1: male1 + female1, PredictorsResponse2: male2 + female2}, nil
+	}
+
+	return Tally{MaleResponse1: male1, MaleResponse2: male2, FemaleResponse1: female1, FemaleResponse2: female2}, nil
+}
+
+// Add returns the sum of two tallies.
+func (t Tally) Add(other Tally) Tally {
+	return Tally{
+		MaleResponse1:       t.MaleResponse1 + other.MaleResponse1,
+		MaleResponse2:       t.MaleResponse2 + other.MaleResponse2,
+		FemaleResponse1:     t.FemaleResponse1 + other.FemaleResponse1,
+		FemaleResponse2:     t.FemaleResponse2 + other.FemaleResponse2,
+		PredictorsResponse1: t.PredictorsResponse1 + other.PredictorsResponse1,
+		PredictorsResponse2: t.PredictorsResponse2 + other.PredictorsResponse2,
+	}
+}
+
+// Response1 returns the votes for response 1 from both genders.
+func (t Tally) Response1() uint32 {
+	return t.MaleResponse1 + t.FemaleResponse1
+}
+
+// Response2 returns the votes for response 2 from both genders.
+func (t Tally) Response2() uint32 {
+	return t.MaleResponse2 + t.FemaleResponse2
+}
+
+func (r *NationalResult) AddTally(t Tally) {
+	r.MaleVotersResponse1 += t.MaleResponse1
+	r.MaleVotersResponse2 += t.MaleResponse2
+	r.FemaleVotersResponse1 += t.FemaleResponse1
+	r.FemaleVotersResponse2 += t.FemaleResponse2
+	r.PredictorsResponse1 += t.PredictorsResponse1
+	r.PredictorsResponse2 += t.PredictorsResponse2
+}
+
+func (r *DetailedNationalResult) AddTally(t Tally) {
+	r.VotersResponse1Number += t.Response1()
+	r.VotersResponse2Number += t.Response2()
+}
+
+func (r *WorldWideResult) AddTally(t Tally) {
+	r.MaleVotersResponse1 += t.MaleResponse1
+	r.MaleVotersResponse2 += t.MaleResponse2
+	r.FemaleVotersResponse1 += t.FemaleResponse1
+	r.FemaleVotersResponse2 += t.FemaleResponse2
+	r.PredictorsResponse1 += t.PredictorsResponse1
+	r.PredictorsResponse2 += t.PredictorsResponse2
+}
+
+func (r *DetailedWorldwideResult) AddTally(t Tally) {
+	r.MaleVotersResponse1 += t.MaleResponse1
+	r.MaleVotersResponse2 += t.MaleResponse2
+	r.FemaleVotersResponse1 += t.FemaleResponse1
+	r.FemaleVotersResponse2 += t.FemaleResponse2
+}
@@ -0,0 +1,36 @@
+package evc
+
+import (
+	"testing"
+)
+
+func TestTallyFromAnsCnt(t *testing.T) {
+	tests := []struct {
+		voteType VoteType
+		ansCnt   int64
+		expected Tally
+	}{
+		{Vote, 1000, Tally{MaleResponse1: 1}},
+		{Vote, 1, Tally{FemaleResponse2: 1}},
+		{Vote, 12, Tally{MaleResponse2: 1, FemaleResponse2: 2}},
+		{Vote, 9876, Tally{MaleResponse1: 9, FemaleResponse1: 8, MaleResponse2: 7, FemaleResponse2: 6}},
+		{Prediction, 1203, Tally{PredictorsResponse1: 3, PredictorsResponse2: 3}},
+	}
+
+	for _, test := range tests {
+		actual, err := TallyFromAnsCnt(test.voteType, test.ansCnt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("%d: expected %+v, got %+v", test.ansCnt, test.expected, actual)
+		}
+	}
+
+	for _, ansCnt := range []int64{10000, -1} {
+		if _, err := TallyFromAnsCnt(Vote, ansCnt); err == nil {
+			t.Errorf("expected %d to be rejected", ansCnt)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package evc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type LocalizedText struct {
+	Japanese       string
+	English        string
+	German         string
+	French         string
+	Spanish        string
+	Italian        string
+	Dutch          string
+	Portuguese     string
+	FrenchCanadian string
+}
+
+type Question struct {
+	ID           int
+	QuestionText LocalizedText
+	Response1    LocalizedText
+	Response2    LocalizedText
+	Category     int
+	Time         time.Time
+}
+
+func GetSupportedLanguages(countryCode uint8) []LanguageCode {
+	return CountriesSupportedLanguages[countryCode]
+}
+
+// Get returns the text for the language, falling back as DefaultFallbacks does.
+func (t LocalizedText) Get(language LanguageCode) string {
+	text, _ := DefaultFallbacks.Resolve(t, 0, language)
+	return text
+}
+
+// Translation returns the text for the language, which is empty if it has not been translated.
+func (t LocalizedText) Translation(language LanguageCode) string {
+	if text := t.translation(language); text != nil {
+		return *text
+	}
+
+	return ""
+}
+
+// Set changes the text for the language.
+func (t *LocalizedText) Set(language LanguageCode, text string) {
+	if translation := t.translation(language); translation != nil {
+		*translation = text
+	}
+}
+
+func (t *LocalizedText) translation(language LanguageCode) *string {
+	switch language {
+	case Japanese:
+		return &t.Japanese
+	case English:
+		return &t.English
+	case German:
+		return &t.German
+	case French:
+		return &t.French
+	case Spanish:
+		return &t.Spanish
+	case Italian:
+		return &t.Italian
+	case Dutch:
+		return &t.Dutch
+	case Portuguese:
+		return &t.Portuguese
+	case FrenchCanadian:
+		return &t.FrenchCanadian
+	}
+
+	return nil
+}
+
+func (v *Votes) GetQuestionForLanguage(question Question, language LanguageCode) string {
+	return v.translate(question, "question", question.QuestionText, language)
+}
+
+func (v *Votes) GetResponse1ForLanguage(question Question, language LanguageCode) string {
+	return v.translate(question, "response1", question.Response1, language)
+}
+
+func (v *Votes) GetResponse2ForLanguage(question Question, language LanguageCode) string {
+	return v.translate(question, "response2", question.Response2, language)
+}
+
+// translate resolves text with the builder's fallback policy and records any fallback used.
+func (v *Votes) translate(question Question, field string, text LocalizedText, language LanguageCode) string {
+	policy := &DefaultFallbacks
+	if v.builder.Fallbacks != nil {
+		policy = v.builder.Fallbacks
+	}
+
+	translated, used := policy.Resolve(text, v.currentCountryCode, language)
+
+	// A file without a worldwide question still has empty text for it.
+	if used != language && question.ID != 0 {
+		v.fallbacks = append(v.fallbacks, Fallback{
+			QuestionID:  question.ID,
+			CountryCode: v.currentCountryCode,
+			Field:       field,
+			Language:    language,
+			Used:        used,
+		})
+	}
+
+	return translated
+}
+
+// SanitizeText wraps every translation to fit the question and response layouts.
+// A translation that cannot fit is removed so the language falls back as if it were untranslated,
+// and is returned in dropped. It only fails if English, which every language falls back to, cannot fit.
+func (q *Question) SanitizeText(layouts Layouts) (dropped []error, err error) {
+	var errs []error
+	for _, field := range []struct {
+		name   string
+		text   *LocalizedText
+		layout Layout
+	}{
+		{"question", &q.QuestionText, layouts.Question},
+		{"response 1", &q.Response1, layouts.Response},
+		{"response 2", &q.Response2, layouts.Response},
+	} {
+		for language := Japanese; language <= FrenchCanadian; language++ {
+			text := field.text.translation(language)
+			wrapped, err := field.layout.Wrap(*text)
+			if err != nil {
+				err = fmt.Errorf("question %d %s %s: %w", q.ID, language, field.name, err)
+				if language == English {
+					errs = append(errs, err)
+				} else {
+					dropped = append(dropped, err)
+					*text = ""
+				}
+
+				continue
+			}
+
+			*text = wrapped
+		}
+	}
+
+	return dropped, errors.Join(errs...)
+}
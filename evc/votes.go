@@ -0,0 +1,219 @@
+package evc
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/wii-tools/lz11"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// Votes contains all the children structs needed to
+// make a voting.bin file.
+type Votes struct {
+	Header                   Header
+	NationalQuestionTable    []QuestionInfo
+	WorldWideQuestionTable   []QuestionInfo
+	QuestionTextInfoTable    []QuestionTextInfo
+	QuestionText             []QuestionText
+	NationalResults          []NationalResult
+	DetailedNationalResults  []DetailedNationalResult
+	PositionEntryTable       []byte
+	WorldwideResults         []WorldWideResult
+	WorldwideResultsDetailed []DetailedWorldwideResult
+	CountryInfoTable         []CountryInfoTable
+	CountryTable             []uint16
+
+	// countryNames are the names in CountryTable, and are only set by Decode.
+	countryNames []string
+	// fallbacks are the texts written in another language as they were not translated.
+	fallbacks []Fallback
+
+	// Static values
+	currentCountryCode uint8
+	builder            *Builder
+	questions          QuestionSet
+	results            ResultSet
+}
+
+// QuestionSet contains the questions placed in a file.
+type QuestionSet struct {
+	National []Question
+	// Worldwide has an ID of 0 if there is no worldwide question.
+	Worldwide Question
+}
+
+// ResultSet contains the results placed in a file.
+type ResultSet struct {
+	National []NationalResult
+	// DetailedNational holds the per-region results for each entry in National.
+	DetailedNational [][]DetailedNationalResult
+	// Worldwide has a PollID of 0 if there is no worldwide result.
+	Worldwide         WorldWideResult
+	DetailedWorldwide []DetailedWorldwideResult
+}
+
+// Builder creates voting files of a single file type and locality.
+type Builder struct {
+	FileType FileType
+	Locality Locality
+	// Time is written as the file's timestamp.
+	Time time.Time
+	// Key signs every file. See LoadPrivateKey.
+	Key *rsa.PrivateKey
+	// Fallbacks chooses the text of untranslated questions. DefaultFallbacks is used if nil.
+	Fallbacks *FallbackPolicy
+}
+
+// BuildVoting creates the signed and compressed file for the passed country.
+func (b *Builder) BuildVoting(questions QuestionSet, results ResultSet, country uint8) ([]byte, error) {
+	votes, err := b.MakeVotes(questions, results, country)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Pack(votes.Encode())
+}
+
+// MakeVotes fills every table required by the builder's file type and locality.
+func (b *Builder) MakeVotes(questions QuestionSet, results ResultSet, country uint8) (*Votes, error) {
+	votes := &Votes{
+		currentCountryCode: country,
+		builder:            b,
+		questions:          questions,
+		results:            results,
+	}
+
+	// Header
+	votes.MakeHeader()
+
+	if b.FileType == Normal || b.FileType == Questions {
+		// Questions
+		if len(questions.National) != 0 {
+			votes.MakeNationalQuestionsTable()
+		}
+
+		if questions.Worldwide.ID != 0 {
+			votes.MakeWorldWideQuestionsTable()
+		}
+
+		if questions.Worldwide.ID != 0 || len(questions.National) != 0 {
+			votes.MakeQuestionsTable()
+		}
+	}
+
+	// National Results
+	if b.FileType == Normal || b.FileType == Results {
+		if b.Locality != Worldwide {
+			votes.MakeNationalResultsTable()
+			votes.MakeDetailedNationalResultsTable()
+			if err := votes.MakePositionTable(); err != nil {
+				return nil, err
+			}
+		}
+
+		if b.Locality != National {
+			votes.MakeWorldWideResultsTable()
+			votes.MakeDetailedWorldWideResults()
+		}
+	}
+
+	if (b.FileType == Normal || b.FileType == Results) && b.Locality != National {
+		// Country Table + Text
+		votes.MakeCountryInfoTable()
+		votes.MakeCountryTable()
+	}
+
+	return votes, nil
+}
+
+// Encode writes the file with its size and CRC32 filled in.
+func (v *Votes) Encode() []byte {
+	buffer := bytes.NewBuffer(nil)
+	v.WriteAll(buffer)
+
+	crcTable := crc32.MakeTable(crc32.IEEE)
+	checksum := crc32.Checksum(buffer.Bytes()[12:], crcTable)
+	v.Header.CRC32 = checksum
+	v.Header.Filesize = uint32(buffer.Len())
+
+	// Reset the temp buffer with the final header
+	buffer.Reset()
+	v.WriteAll(buffer)
+
+	return buffer.Bytes()
+}
+
+// InputHash returns the SHA-256 of an encoded file with its CRC32 and timestamp cleared.
+// It only changes when the questions, results or header options do.
+func InputHash(encoded []byte) string {
+	data := bytes.Clone(encoded)
+	if len(data) >= 16 {
+		copy(data[8:16], make([]byte, 8))
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// Pack compresses and signs an encoded file.
+func (b *Builder) Pack(data []byte) ([]byte, error) {
+	compressed, err := lz11.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return SignFile(b.Key, compressed)
+}
+
+// Write writes the current values in Votes to an io.Writer method.
+// This is required as Go cannot write structs with non-fixed slice sizes,
+// but can write them individually.
+func (v *Votes) Write(writer io.Writer, data interface{}) {
+	// Every value written is of a fixed size, so this can only fail on a programming error.
+	err := binary.Write(writer, binary.BigEndian, data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (v *Votes) WriteAll(writer io.Writer) {
+	v.Write(writer, v.Header)
+
+	// Questions
+	v.Write(writer, v.NationalQuestionTable)
+	v.Write(writer, v.WorldWideQuestionTable)
+	v.Write(writer, v.QuestionTextInfoTable)
+
+	// Go doesn't like nested slices in structs.
+	for _, question := range v.QuestionText {
+		v.Write(writer, question.Question)
+		v.Write(writer, question.Response1)
+		v.Write(writer, question.Response2)
+	}
+
+	// National Results
+	v.Write(writer, v.NationalResults)
+	v.Write(writer, v.DetailedNationalResults)
+	v.Write(writer, v.PositionEntryTable)
+
+	// Worldwide Results
+	v.Write(writer, v.WorldwideResults)
+	v.Write(writer, v.WorldwideResultsDetailed)
+
+	v.Write(writer, v.CountryInfoTable)
+	v.Write(writer, v.CountryTable)
+}
+
+// GetCurrentSize returns the current size of our Votes struct.
+// This is useful for calculating the current offset of Votes.
+func (v *Votes) GetCurrentSize() uint32 {
+	buffer := bytes.NewBuffer([]byte{})
+	v.WriteAll(buffer)
+
+	return uint32(buffer.Len())
+}
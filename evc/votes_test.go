@@ -0,0 +1,99 @@
+package evc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+func TestBuildVoting(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	question := Question{
+		ID:           1,
+		QuestionText: LocalizedText{English: "Cats or dogs?"},
+		Response1:    LocalizedText{English: "Cats"},
+		Response2:    LocalizedText{English: "Dogs"},
+		Time:         time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	builder := Builder{FileType: Normal, Locality: All, Time: question.Time, Key: key}
+	worldwide := question
+	worldwide.ID = 2
+
+	votes, err := builder.MakeVotes(QuestionSet{National: []Question{question}, Worldwide: worldwide}, ResultSet{}, 110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := votes.Encode()
+	if size := binary.BigEndian.Uint32(encoded[4:]); size != uint32(len(encoded)) {
+		t.Errorf("file size is %d, expected %d", size, len(encoded))
+	}
+
+	if checksum := binary.BigEndian.Uint32(encoded[8:]); checksum != crc32.ChecksumIEEE(encoded[12:]) {
+		t.Errorf("CRC32 does not match contents")
+	}
+
+	if country := encoded[16]; country != 110 {
+		t.Errorf("country code is %d, expected 110", country)
+	}
+
+	// The United Kingdom only supports English.
+	if votes.Header.NumberOfNationalQuestions != 1 || votes.Header.NumberOfWorldWideQuestions != 1 || votes.Header.NumberOfQuestions != 2 {
+		t.Errorf("unexpected question counts in header: %+v", votes.Header)
+	}
+
+	// Generating the same file later must not change the input hash.
+	later := builder
+	later.Time = later.Time.Add(time.Hour)
+	laterVotes, err := later.MakeVotes(QuestionSet{National: []Question{question}, Worldwide: worldwide}, ResultSet{}, 110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	laterEncoded := laterVotes.Encode()
+	if bytes.Equal(laterEncoded, encoded) || InputHash(laterEncoded) != InputHash(encoded) {
+		t.Errorf("input hash should ignore the timestamp")
+	}
+
+	laterVotes, err = later.MakeVotes(QuestionSet{National: []Question{question}, Worldwide: worldwide}, ResultSet{}, 111)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if InputHash(laterVotes.Encode()) == InputHash(encoded) {
+		t.Errorf("input hash should change with the contents")
+	}
+
+	data, err := builder.Pack(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unpacked, err := Unpack(data, &key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(unpacked, encoded) {
+		t.Errorf("unpacked file does not match the encoded file")
+	}
+
+	// Files can be read without the key, but are then only checked against their CRC32.
+	if unpacked, err = UnpackUnverified(data); err != nil || !bytes.Equal(unpacked, encoded) {
+		t.Errorf("expected the file to unpack without a key: %v", err)
+	}
+
+	data[len(data)-1] ^= 0xff
+	if _, err = Unpack(data, &key.PublicKey); err == nil {
+		t.Errorf("expected a modified file to fail verification")
+	}
+}
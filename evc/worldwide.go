@@ -1,4 +1,4 @@
-package main
+package evc
 
 // WorldWideResult contains the overall results for a worldwide question.
 type WorldWideResult struct {
@@ -29,28 +29,28 @@ type DetailedWorldwideResult struct {
 func (v *Votes) MakeWorldWideQuestionsTable() {
 	v.Header.WorldWideQuestionTableOffset = v.GetCurrentSize()
 
-	entryNum := len(v.NationalQuestionTable) * len(countriesSupportedLanguages[v.currentCountryCode])
+	entryNum := len(v.NationalQuestionTable) * len(CountriesSupportedLanguages[v.currentCountryCode])
 
 	v.WorldWideQuestionTable = append(v.WorldWideQuestionTable, QuestionInfo{
-		PollID:                     uint32(worldwideQuestion.ID),
-		PollCategory1:              uint8(worldwideQuestion.Category),
-		PollCategory2:              categoryKV[worldwideQuestion.Category],
-		StartingTimestamp:          CreateTimestamp(int(worldwideQuestion.Time.Unix())),
-		EndingTimestamp:            CreateTimestamp(int(worldwideQuestion.Time.Unix())) + 21600,
-		NumberOfSupportedLanguages: uint8(len(countriesSupportedLanguages[v.currentCountryCode])),
+		PollID:                     uint32(v.questions.Worldwide.ID),
+		PollCategory1:              uint8(v.questions.Worldwide.Category),
+		PollCategory2:              CategoryKV[v.questions.Worldwide.Category],
+		StartingTimestamp:          CreateTimestamp(int(v.questions.Worldwide.Time.Unix())),
+		EndingTimestamp:            CreateTimestamp(int(v.questions.Worldwide.Time.Unix())) + 21600,
+		NumberOfSupportedLanguages: uint8(len(CountriesSupportedLanguages[v.currentCountryCode])),
 		QuestionTableEntryNumber:   uint32(entryNum),
 	})
 
-	entryNum += len(countriesSupportedLanguages[v.currentCountryCode])
+	entryNum += len(CountriesSupportedLanguages[v.currentCountryCode])
 
 	v.Header.NumberOfWorldWideQuestions = uint8(len(v.WorldWideQuestionTable))
 }
 
 // MakeWorldWideResultsTable creates the results for the current national question.
 func (v *Votes) MakeWorldWideResultsTable() {
-	if worldWideResult.PollID != 0 {
+	if v.results.Worldwide.PollID != 0 {
 		v.Header.WorldWideResultsTableOffset = v.GetCurrentSize()
-		v.WorldwideResults = append(v.WorldwideResults, worldWideResult)
+		v.WorldwideResults = append(v.WorldwideResults, v.results.Worldwide)
 	}
 
 	v.Header.NumberOfWorldWideResults = uint8(len(v.WorldwideResults))
@@ -60,6 +60,6 @@ func (v *Votes) MakeWorldWideResultsTable() {
 func (v *Votes) MakeDetailedWorldWideResults() {
 	v.Header.DetailedWorldWideResultTableOffset = v.GetCurrentSize()
 
-	v.WorldwideResultsDetailed = worldWideDetailedResults
+	v.WorldwideResultsDetailed = v.results.DetailedWorldwide
 	v.Header.NumberOfDetailedWorldWideResults = uint16(len(v.WorldwideResultsDetailed))
 }
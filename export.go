@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteExport writes records to path as either an indented JSON array or CSV,
+// where each record is converted to a CSV row by row.
+func WriteExport[T any](path string, format string, records []T, header []string, row func(T) []string) error {
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown export format %q, expected csv or json", format)
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		if records == nil {
+			records = []T{}
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(records)
+	case "csv":
+		writer := csv.NewWriter(file)
+		err = writer.Write(header)
+		for _, record := range records {
+			if err != nil {
+				break
+			}
+
+			err = writer.Write(row(record))
+		}
+
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// QueryQuestionsClosedSince queries the questions whose results were published between two times.
+	QueryQuestionsClosedSince = `SELECT question_id, type FROM questions
+							WHERE (type = 'n' AND date > $1 AND date <= $2)
+							OR (type = 'w' AND date > $3 AND date <= $4)
+							ORDER BY date, question_id`
+
+	// QueryQuestionTypes queries the type of each of the passed questions.
+	QueryQuestionTypes = `SELECT question_id, type FROM questions
+							WHERE question_id = ANY($1)
+							ORDER BY date, question_id`
+)
+
+// ResultRow is the votes for a question from one gender in one region.
+// Rows with the gender "all" combine both genders and also carry the predictions.
+type ResultRow struct {
+	QuestionID           int     `json:"question_id"`
+	Worldwide            bool    `json:"worldwide"`
+	CountryCode          uint8   `json:"country"`
+	RegionID             int     `json:"region"`
+	Gender               string  `json:"gender"`
+	Response1            uint32  `json:"response1"`
+	Response2            uint32  `json:"response2"`
+	PredictionsResponse1 *uint32 `json:"predictions_response1,omitempty"`
+	PredictionsResponse2 *uint32 `json:"predictions_response2,omitempty"`
+	// Language is the language of the text, which is the first the country supports.
+	Language      string `json:"language"`
+	QuestionText  string `json:"question_text"`
+	Response1Text string `json:"response1_text"`
+	Response2Text string `json:"response2_text"`
+}
+
+// RunExportResults writes the results of questions as one row per country, region and gender.
+func RunExportResults(args []string, currentTime time.Time, minimumVoters uint32) {
+	flags := flag.NewFlagSet("export-results", flag.ExitOnError)
+	questionsStr := flags.String("questions", "", "comma separated question IDs to export (default those closed in the last --days)")
+	days := flags.Int("days", 30, "export the questions whose results were published in this many days before the as-of time")
+	format := flags.String("format", "csv", "output format (csv or json)")
+	out := flags.String("out", "", "file to write to (default results.<format>)")
+	checkError(flags.Parse(args))
+
+	if *out == "" {
+		*out = "results." + *format
+	}
+
+	var questionIDs []int
+	if *questionsStr != "" {
+		var err error
+		questionIDs, err = ParseQuestionIDs(*questionsStr)
+		checkError(err)
+	}
+
+	ctx := context.Background()
+	pool := ConnectDatabase(ctx)
+	defer pool.Close()
+
+	var rows []ResultRow
+	questions, err := exportedQuestions(ctx, pool, questionIDs, currentTime, *days)
+	checkError(err)
+
+	// Exporting never quarantines votes, as it only reads them.
+	generator := NewGenerator(ctx, pool, nil, evc.Normal, evc.National, currentTime)
+	generator.options = ResultOptions{MinimumVoters: minimumVoters}
+	for _, question := range questions {
+		tallies, err := questionTallies(generator, question.ID)
+		checkError(err)
+		HideBelowThreshold(tallies, question.Worldwide, minimumVoters)
+		rows = append(rows, ResultRows(question.Question, question.Worldwide, tallies)...)
+	}
+
+	header := []string{"question_id", "worldwide", "country", "region", "gender", "response1", "response2",
+		"predictions_response1", "predictions_response2", "language", "question_text", "response1_text", "response2_text"}
+	checkError(WriteExport(*out, *format, rows, header, func(row ResultRow) []string {
+		predictions1, predictions2 := "", ""
+		if row.PredictionsResponse1 != nil {
+			predictions1 = strconv.FormatUint(uint64(*row.PredictionsResponse1), 10)
+			predictions2 = strconv.FormatUint(uint64(*row.PredictionsResponse2), 10)
+		}
+
+		return []string{
+			strconv.Itoa(row.QuestionID), strconv.FormatBool(row.Worldwide), ZFill(row.CountryCode, 3), strconv.Itoa(row.RegionID),
+			row.Gender, strconv.FormatUint(uint64(row.Response1), 10), strconv.FormatUint(uint64(row.Response2), 10),
+			predictions1, predictions2, row.Language, row.QuestionText, row.Response1Text, row.Response2Text,
+		}
+	}))
+
+	fmt.Printf("Exported %d rows for %d questions to %s.\n", len(rows), len(questions), *out)
+	for _, row := range generator.invalidRows {
+		fmt.Printf("question %d country %s: %d votes rows were left out as they cannot be read (%s)\n",
+			row.QuestionID, ZFill(row.CountryCode, 3), row.Rows, row.Error)
+	}
+}
+
+type exportedQuestion struct {
+	evc.Question
+	Worldwide bool
+}
+
+// exportedQuestions returns the passed questions, or those whose results were published in the days before currentTime.
+func exportedQuestions(ctx context.Context, pool *pgxpool.Pool, questionIDs []int, currentTime time.Time, days int) ([]exportedQuestion, error) {
+	// National questions close after 7 days and worldwide questions after 15.
+	nationalClosed := currentTime.AddDate(0, 0, -7)
+	worldwideClosed := currentTime.AddDate(0, 0, -15)
+
+	var rows pgx.Rows
+	var err error
+	if questionIDs != nil {
+		rows, err = pool.Query(ctx, QueryQuestionTypes, questionIDs)
+	} else {
+		rows, err = pool.Query(ctx, QueryQuestionsClosedSince,
+			nationalClosed.AddDate(0, 0, -days), nationalClosed, worldwideClosed.AddDate(0, 0, -days), worldwideClosed)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []exportedQuestion
+	for rows.Next() {
+		var question exportedQuestion
+		var questionType string
+		if err = rows.Scan(&question.ID, &questionType); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		question.Worldwide = questionType == "w"
+		questions = append(questions, question)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, question := range questions {
+		questions[i].Question, err = scanQuestion(pool.QueryRow(ctx, QueryQuestion, question.ID))
+		if err != nil {
+			return nil, fmt.Errorf("question %d: %w", question.ID, err)
+		}
+	}
+
+	return questions, nil
+}
+
+// questionTallies tallies the votes and predictions for a question by country and region,
+// counting the same rows PrepareNationalResults does.
+func questionTallies(generator *Generator, questionID int) (map[regionKey]evc.Tally, error) {
+	tallies := map[regionKey]evc.Tally{}
+	for _, countryCode := range evc.CountryCodes {
+		votes, err := generator.tallyNationalVotes(questionID, countryCode)
+		if err != nil {
+			return nil, fmt.Errorf("question %d country %d: %w", questionID, countryCode, err)
+		}
+
+		for _, byRegion := range []map[int]evc.Tally{votes.Votes, votes.Predictions} {
+			for regionID, tally := range byRegion {
+				key := regionKey{countryCode, regionID}
+				tallies[key] = tallies[key].Add(tally)
+			}
+		}
+	}
+
+	return tallies, nil
+}
+
+// HideBelowThreshold removes the regions whose results the published files hide for having fewer than minimum voters,
+// following evc.ApplyNationalThreshold within each country. For worldwide questions every region of a country hidden
+// by evc.ApplyWorldwideThreshold is also removed.
+func HideBelowThreshold(tallies map[regionKey]evc.Tally, worldwide bool, minimum uint32) {
+	byCountry := map[uint8][]regionKey{}
+	for key := range tallies {
+		byCountry[key.countryCode] = append(byCountry[key.countryCode], key)
+	}
+
+	countryCodes := make([]uint8, 0, len(byCountry))
+	for countryCode := range byCountry {
+		countryCodes = append(countryCodes, countryCode)
+	}
+
+	slices.Sort(countryCodes)
+	countries := make([]evc.DetailedWorldwideResult, len(countryCodes))
+	for i, countryCode := range countryCodes {
+		keys := byCountry[countryCode]
+		var result evc.NationalResult
+		regions := make([]evc.DetailedNationalResult, len(keys))
+		for j, key := range keys {
+			result.AddTally(tallies[key])
+			regions[j].AddTally(tallies[key])
+			countries[i].AddTally(tallies[key])
+		}
+
+		evc.ApplyNationalThreshold(&result, regions, minimum)
+		for j, key := range keys {
+			// Regions with only predictions are never hidden, as predictions are only published for the whole country.
+			if voted := tallies[key].Response1()+tallies[key].Response2() != 0; voted && regions[j].VotersResponse1Number+regions[j].VotersResponse2Number == 0 {
+				delete(tallies, key)
+			}
+		}
+	}
+
+	if !worldwide {
+		return
+	}
+
+	voters := func(country evc.DetailedWorldwideResult) uint32 {
+		return country.MaleVotersResponse1 + country.MaleVotersResponse2 + country.FemaleVotersResponse1 + country.FemaleVotersResponse2
+	}
+
+	shown := slices.Clone(countries)
+	evc.ApplyWorldwideThreshold(shown, minimum)
+	for i, countryCode := range countryCodes {
+		if voters(countries[i]) == 0 || voters(shown[i]) != 0 {
+			continue
+		}
+
+		for _, key := range byCountry[countryCode] {
+			delete(tallies, key)
+		}
+	}
+}
+
+// ResultRows returns the rows for a question from its tallies, sorted by country and region.
+func ResultRows(question evc.Question, worldwide bool, tallies map[regionKey]evc.Tally) []ResultRow {
+	keys := make([]regionKey, 0, len(tallies))
+	for key := range tallies {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].countryCode != keys[j].countryCode {
+			return keys[i].countryCode < keys[j].countryCode
+		}
+
+		return keys[i].regionID < keys[j].regionID
+	})
+
+	var rows []ResultRow
+	for _, key := range keys {
+		tally := tallies[key]
+		row := ResultRow{
+			QuestionID:    question.ID,
+			Worldwide:     worldwide,
+			CountryCode:   key.countryCode,
+			RegionID:      key.regionID,
+			Language:      evc.English.String(),
+			QuestionText:  question.QuestionText.English,
+			Response1Text: question.Response1.English,
+			Response2Text: question.Response2.English,
+		}
+
+		if languages := evc.GetSupportedLanguages(key.countryCode); len(languages) != 0 {
+			row.Language = languages[0].String()
+			row.QuestionText = question.QuestionText.Get(languages[0])
+			row.Response1Text = question.Response1.Get(languages[0])
+			row.Response2Text = question.Response2.Get(languages[0])
+		}
+
+		male, female, all := row, row, row
+		male.Gender, male.Response1, male.Response2 = "male", tally.MaleResponse1, tally.MaleResponse2
+		female.Gender, female.Response1, female.Response2 = "female", tally.FemaleResponse1, tally.FemaleResponse2
+		all.Gender, all.Response1, all.Response2 = "all", tally.Response1(), tally.Response2()
+		all.PredictionsResponse1, all.PredictionsResponse2 = &tally.PredictorsResponse1, &tally.PredictorsResponse2
+
+		rows = append(rows, male, female, all)
+	}
+
+	return rows
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"testing"
+)
+
+func TestResultRows(t *testing.T) {
+	question := evc.Question{
+		ID:           7,
+		QuestionText: evc.LocalizedText{English: "Summer or winter?", German: "Sommer oder Winter?"},
+		Response1:    evc.LocalizedText{English: "Summer", German: "Sommer"},
+		Response2:    evc.LocalizedText{English: "Winter", German: "Winter"},
+	}
+
+	tallies := map[regionKey]evc.Tally{
+		{110, 3}: {MaleResponse1: 4, FemaleResponse2: 1, PredictorsResponse1: 2},
+		{78, 2}:  {FemaleResponse1: 5},
+	}
+
+	rows := ResultRows(question, true, tallies)
+	if len(rows) != 6 {
+		t.Fatalf("expected 3 rows for each region, got %d", len(rows))
+	}
+
+	// Germany sorts first and its text is in German.
+	if rows[0].CountryCode != 78 || rows[0].Language != "de" || rows[0].QuestionText != "Sommer oder Winter?" {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+
+	if female := rows[1]; female.Gender != "female" || female.Response1 != 5 || female.PredictionsResponse1 != nil {
+		t.Errorf("unexpected female row %+v", female)
+	}
+
+	all := rows[5]
+	if all.CountryCode != 110 || all.Gender != "all" || all.Response1 != 4 || all.Response2 != 1 || *all.PredictionsResponse1 != 2 {
+		t.Errorf("unexpected combined row %+v", all)
+	}
+}
+
+func TestHideBelowThreshold(t *testing.T) {
+	tallies := map[regionKey]evc.Tally{
+		{110, 2}: {MaleResponse1: 6},
+		{110, 3}: {FemaleResponse2: 2},
+		{110, 4}: {MaleResponse2: 5},
+		{110, 5}: {PredictorsResponse1: 1},
+		{78, 2}:  {FemaleResponse1: 3},
+		{1, 2}:   {MaleResponse2: 2},
+	}
+
+	HideBelowThreshold(tallies, true, 5)
+
+	// Region 3 is below the threshold, and hiding it alone would let it be recovered from the total,
+	// so region 4 is hidden with it. Germany and Japan have too few voters to be shown at all.
+	for _, key := range []regionKey{{110, 3}, {110, 4}, {78, 2}, {1, 2}} {
+		if _, ok := tallies[key]; ok {
+			t.Errorf("expected country %d region %d to be hidden", key.countryCode, key.regionID)
+		}
+	}
+
+	for _, key := range []regionKey{{110, 2}, {110, 5}} {
+		if _, ok := tallies[key]; !ok {
+			t.Errorf("expected country %d region %d to be kept", key.countryCode, key.regionID)
+		}
+	}
+}
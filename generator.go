@@ -0,0 +1,320 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ResultOptions control how votes are turned into results.
+type ResultOptions struct {
+	// MinimumVoters is the fewest voters a region or country must have for its results to be shown.
+	MinimumVoters uint32
+	// Quarantine moves votes which cannot be placed in the detailed results out of the votes table.
+	// It has no effect on dry runs.
+	Quarantine bool
+}
+
+// Generator holds the state of a single generation run.
+// Nothing in it is written to once Prepare has returned, which allows
+// every country to be generated concurrently.
+type Generator struct {
+	ctx         context.Context
+	pool        *pgxpool.Pool
+	builder     *evc.Builder
+	logger      *slog.Logger
+	fileType    evc.FileType
+	locality    evc.Locality
+	currentTime time.Time
+	// outputDir is where files are written, usually a Publisher's staging directory.
+	outputDir string
+	// dryRun builds every file without writing any of them.
+	dryRun  bool
+	options ResultOptions
+	// charset is the characters questions may use. evc.DefaultCharset is used if nil.
+	charset *evc.Charset
+	// layouts are the spaces question text is wrapped to fit, evc.DefaultLayouts unless changed.
+	layouts evc.Layouts
+	// fallbackLongText shows translations too long to fit in another language rather than failing.
+	fallbackLongText bool
+	// previous is the last published manifest. Countries whose inputs match it are skipped.
+	// If nil every country is generated.
+	previous *Manifest
+
+	// questions are shared by every country.
+	questions evc.QuestionSet
+	// results only contains the worldwide results, as national results are per country.
+	results evc.ResultSet
+
+	mismatches      []Mismatch
+	mismatchesMutex sync.Mutex
+
+	fallbacks      []evc.Fallback
+	fallbacksMutex sync.Mutex
+
+	// dropped are the translations left out by Prepare as they do not fit.
+	dropped []string
+	// replaced are the characters Prepare replaced as the font cannot draw them.
+	replaced []string
+
+	invalidRows      []InvalidRow
+	invalidRowsMutex sync.Mutex
+}
+
+func NewGenerator(ctx context.Context, pool *pgxpool.Pool, key *rsa.PrivateKey, fileType evc.FileType, locality evc.Locality, currentTime time.Time) *Generator {
+	return &Generator{
+		ctx:         ctx,
+		pool:        pool,
+		builder:     &evc.Builder{FileType: fileType, Locality: locality, Time: currentTime, Key: key},
+		logger:      logger.With("file_type", fileType, "locality", locality),
+		fileType:    fileType,
+		locality:    locality,
+		currentTime: currentTime,
+		outputDir:   OutputRoot,
+		layouts:     evc.DefaultLayouts,
+	}
+}
+
+// Prepare queries the questions and results that are shared by every country.
+func (g *Generator) Prepare() error {
+	var err error
+	if g.fileType == evc.Normal {
+		// voting.bin requires all questions and all applicable results.
+		if err = g.PrepareNationalQuestions(); err != nil {
+			return err
+		}
+
+		if err = g.PrepareWorldWideQuestion(); err != nil {
+			return err
+		}
+
+		err = g.PrepareWorldWideResults()
+	} else if g.fileType == evc.Results {
+		// National results will generate themselves
+		if g.locality == evc.Worldwide {
+			err = g.PrepareWorldWideResults()
+		}
+	} else if g.fileType == evc.Questions {
+		if g.locality == evc.Worldwide {
+			err = g.PrepareWorldWideQuestion()
+		} else {
+			err = g.PrepareNationalQuestions()
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	evc.ApplyWorldwideThreshold(g.results.DetailedWorldwide, g.options.MinimumVoters)
+
+	g.logger.Info("prepared questions",
+		"national_question_ids", questionIDs(g.questions.National),
+		"worldwide_question_id", g.questions.Worldwide.ID,
+		"worldwide_result_id", g.results.Worldwide.PollID,
+	)
+
+	return nil
+}
+
+// GeneratedFile describes the file created for a single country.
+type GeneratedFile struct {
+	// Path is relative to the output directory.
+	Path        string     `json:"path"`
+	Size        int        `json:"size"`
+	SHA256      string     `json:"sha256"`
+	QuestionIDs []uint32   `json:"question_ids,omitempty"`
+	ResultIDs   []uint32   `json:"result_ids,omitempty"`
+	InputHash   string     `json:"input_hash,omitempty"`
+	Header      evc.Header `json:"header"`
+	data        []byte
+	// asOf overrides the publisher's time for files that are republished.
+	asOf time.Time
+}
+
+// ErrUnchanged is returned by Generate when a country's inputs match the published file.
+var ErrUnchanged = errors.New("inputs unchanged since last published")
+
+// Generate creates the file for a single country and writes it unless this is a dry run.
+func (g *Generator) Generate(countryCode uint8) (*GeneratedFile, error) {
+	logger := g.logger.With("country", countryCode)
+	logger.Debug("generating file")
+
+	results := g.results
+	var err error
+	if (g.fileType == evc.Normal || g.fileType == evc.Results) && g.locality != evc.Worldwide {
+		results.National, results.DetailedNational, err = g.PrepareNationalResults(countryCode, logger)
+		if err != nil {
+			return nil, fmt.Errorf("national results: %w", err)
+		}
+
+		for i := range results.National {
+			evc.ApplyNationalThreshold(&results.National[i], results.DetailedNational[i], g.options.MinimumVoters)
+		}
+	}
+
+	votes, err := g.builder.MakeVotes(g.questions, results, countryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	g.fallbacksMutex.Lock()
+	g.fallbacks = append(g.fallbacks, votes.Fallbacks()...)
+	g.fallbacksMutex.Unlock()
+
+	encoded := votes.Encode()
+	inputHash := evc.InputHash(encoded)
+	if g.previous != nil {
+		if entry, ok := g.previous.Get(g.GetPath(countryCode)); ok && entry.InputHash == inputHash {
+			logger.Debug("skipping unchanged file", "input_hash", inputHash)
+			return nil, ErrUnchanged
+		}
+	}
+
+	signed, err := g.builder.Pack(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("pack: %w", err)
+	}
+
+	logger.Debug("signed file", "encoded_size", len(encoded), "signed_size", len(signed), "crc32", votes.Header.CRC32)
+
+	file := NewGeneratedFile(g.GetPath(countryCode), signed)
+	file.Header = votes.Header
+	file.InputHash = inputHash
+
+	for _, question := range append(votes.NationalQuestionTable, votes.WorldWideQuestionTable...) {
+		file.QuestionIDs = append(file.QuestionIDs, question.PollID)
+	}
+
+	for _, result := range votes.NationalResults {
+		file.ResultIDs = append(file.ResultIDs, result.PollID)
+	}
+
+	for _, result := range votes.WorldwideResults {
+		file.ResultIDs = append(file.ResultIDs, result.PollID)
+	}
+
+	if g.dryRun {
+		logger.Info("planned file", "path", file.Path, "size", file.Size, "question_ids", file.QuestionIDs, "result_ids", file.ResultIDs)
+		return file, nil
+	}
+
+	// Create the file to write to, along with any directories it needs.
+	path := filepath.Join(g.outputDir, file.Path)
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(path, signed, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("wrote file",
+		"path", path,
+		"size", file.Size,
+		"national_questions", votes.Header.NumberOfNationalQuestions,
+		"worldwide_questions", votes.Header.NumberOfWorldWideQuestions,
+		"national_results", votes.Header.NumberOfNationalResults,
+		"worldwide_results", votes.Header.NumberOfWorldWideResults,
+	)
+
+	return file, nil
+}
+
+// skipInvalidRow logs and records a votes row whose tally cannot be read, such as a legacy ans_cnt
+// too large for its digits. The row is left out so one bad historical row does not stop the run.
+func (g *Generator) skipInvalidRow(questionID int, countryCode uint8, err error) {
+	g.logger.Warn("skipping a votes row that cannot be read", "question_id", questionID, "country", countryCode, "error", err)
+
+	g.invalidRowsMutex.Lock()
+	defer g.invalidRowsMutex.Unlock()
+	for i, row := range g.invalidRows {
+		if row.QuestionID == questionID && row.CountryCode == countryCode {
+			g.invalidRows[i].Rows++
+			return
+		}
+	}
+
+	g.invalidRows = append(g.invalidRows, InvalidRow{QuestionID: questionID, CountryCode: countryCode, Rows: 1, Error: err.Error()})
+}
+
+// Run prepares the shared data then generates the passed countries.
+// If preparation fails every country is skipped and the error is returned.
+func (g *Generator) Run(countryCodes []uint8, workers int, report *Report) error {
+	err := g.Prepare()
+	if err != nil {
+		// Without the shared questions and results no file would be correct.
+		g.logger.Error("failed to prepare shared data", "error", err)
+		for _, countryCode := range countryCodes {
+			report.Skipped(countryCode, fmt.Sprintf("shared preparation failed: %v", err))
+		}
+
+		return err
+	}
+
+	g.GenerateAll(countryCodes, workers, report)
+	report.AddMismatches(g.mismatches)
+	report.AddFallbacks(g.fallbacks)
+	report.DroppedTranslations = g.dropped
+	report.ReplacedCharacters = g.replaced
+	report.AddInvalidRows(g.invalidRows)
+	return nil
+}
+
+// GetPath returns the path of the country's file relative to the output directory.
+func (g *Generator) GetPath(countryCode uint8) string {
+	return ZFill(countryCode, 3) + "/" + g.GetFilename()
+}
+
+// GenerateAll generates the passed Countries with a bounded number of workers.
+// Results are recorded in the order of countryCodes regardless of completion order.
+func (g *Generator) GenerateAll(countryCodes []uint8, workers int, report *Report) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	files := make([]*GeneratedFile, len(countryCodes))
+	errs := make([]error, len(countryCodes))
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				files[index], errs[index] = g.Generate(countryCodes[index])
+			}
+		}()
+	}
+
+	for index := range countryCodes {
+		jobs <- index
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	for i, countryCode := range countryCodes {
+		if errors.Is(errs[i], ErrUnchanged) {
+			report.Skipped(countryCode, errs[i].Error())
+			continue
+		} else if errs[i] != nil {
+			g.logger.Error("failed to generate file", "country", countryCode, "error", errs[i])
+			report.Failed(countryCode, errs[i])
+			continue
+		}
+
+		report.Succeeded(countryCode, files[i])
+	}
+}
@@ -1,10 +1,9 @@
 module EverybodyVotesChannel
 
-go 1.18
+go 1.21
 
 require (
 	github.com/jackc/pgx/v4 v4.15.0
-	github.com/mitchellh/go-wordwrap v1.0.1
 	github.com/wii-tools/lz11 v0.2.0
 )
 
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logger is the structured logger used throughout the generator.
+// It defaults to text output on stderr until SetupLogger is called.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+// SetupLogger replaces the global logger with one using the requested
+// output format (text or json) and minimum level.
+func SetupLogger(format string, level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	options := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "text":
+		logger = slog.New(slog.NewTextHandler(os.Stderr, options))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, options))
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+
+	slog.SetDefault(logger)
+	return nil
+}
+
+// questionIDs returns the IDs of the passed questions for logging.
+func questionIDs(questions []evc.Question) []int {
+	ids := make([]int, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+
+	return ids
+}
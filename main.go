@@ -1,228 +1,269 @@
 package main
 
 import (
-	"bytes"
+	"EverybodyVotesChannel/evc"
 	"context"
-	"encoding/binary"
+	"flag"
 	"fmt"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"github.com/wii-tools/lz11"
-	"hash/crc32"
-	"io"
-	"log"
 	"os"
-	"time"
-)
-
-// Votes contains all the children structs needed to
-// make a voting.bin file.
-type Votes struct {
-	Header                   Header
-	NationalQuestionTable    []QuestionInfo
-	WorldWideQuestionTable   []QuestionInfo
-	QuestionTextInfoTable    []QuestionTextInfo
-	QuestionText             []QuestionText
-	NationalResults          []NationalResult
-	DetailedNationalResults  []DetailedNationalResult
-	PositionEntryTable       []byte
-	WorldwideResults         []WorldWideResult
-	WorldwideResultsDetailed []DetailedWorldwideResult
-	CountryInfoTable         []CountryInfoTable
-	CountryTable             []uint16
-
-	// Static values
-	currentCountryCode  uint8
-	tempDetailedResults [][]DetailedNationalResult
-}
-
-// SQL variables.
-var (
-	pool        *pgxpool.Pool
-	ctx         = context.Background()
-	fileType    FileType
-	locality    Locality
-	currentTime time.Time
+	"runtime"
 )
 
 func checkError(err error) {
 	if err != nil {
-		log.Fatalf("Everybody Votes Channel file generator has encountered a fatal error! Reason: %v\n", err)
+		logger.Error("Everybody Votes Channel file generator has encountered a fatal error!", "error", err)
+		os.Exit(1)
 	}
 }
 
 func main() {
-	currentTime = time.Now()
-	err := os.WriteFile("votes/first_data.bin", MakeFirstData(), 0666)
-	checkError(err)
-
-	fileType = GetFileType(os.Args[1])
-	if len(os.Args) >= 3 {
-		locality = GetLocality(os.Args[2])
-	} else {
-		locality = All
-	}
-
-	// Get config
-	config := GetConfig()
+	logFormat := flag.String("log-format", "text", "log output format (text or json)")
+	logLevel := flag.String("log-level", "info", "minimum log level (debug, info, warn or error)")
+	reportPath := flag.String("report", "", "write a JSON report of the run to this path")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of countries to generate concurrently")
+	asOf := flag.String("as-of", "", "generate as of this date (YYYY-MM-DD) or time (RFC 3339) instead of now")
+	countriesStr := flag.String("countries", "", "comma separated country codes to generate (default all)")
+	partial := flag.Bool("partial", false, "publish the countries that succeeded even if others failed")
+	output := flag.String("output", OutputRoot, "where to publish: a directory, a .tar or .zip bundle, or s3://bucket/prefix")
+	archiveDir := flag.String("archive", "archive", "directory keeping every published file, or empty to disable")
+	minimumVoters := flag.Uint("min-voters", 5, "hide the results of regions and countries with fewer voters than this")
+	quarantine := flag.Bool("quarantine", false, "move votes from unknown regions or countries to the votes_quarantine table once the files are published")
+	full := flag.Bool("full", false, "regenerate every country, even those whose inputs are unchanged since the last publish")
+	fallbackLongText := flag.Bool("fallback-long-text", false, "show translations too long to fit in their fallback language instead of failing the run")
+	dryRun := flag.Bool("dry-run", false, "query and build every file, then print what would be written without writing anything")
+	flag.Usage = func() {
+		commands := []string{
+			"v|r|q [w|n]",
+			"backfill [backfill flags]",
+			"rollback --to <time>",
+			"score [score flags]",
+			"export-results [export flags]",
+			"render-maps [--out <dir>]",
+			"positions show|check|edit <country> [edit flags]",
+			"preview [--language <code>] [--key <pem>] <file>",
+			"coverage [--weeks <n>]",
+			"check-text [--questions <ids>] [--weeks <n>]",
+			"translations export|import [translation flags]",
+		}
 
-	// Start SQL
-	dbString := fmt.Sprintf("postgres://%s:%s@%s/%s", config.Username, config.Password, config.DatabaseAddress, config.DatabaseName)
-	dbConf, err := pgxpool.ParseConfig(dbString)
-	checkError(err)
-	pool, err = pgxpool.ConnectConfig(ctx, dbConf)
-	checkError(err)
+		for i, command := range commands {
+			prefix := "Usage:"
+			if i != 0 {
+				prefix = "      "
+			}
 
-	defer pool.Close()
+			fmt.Fprintf(flag.CommandLine.Output(), "%s %s [flags] %s\n", prefix, os.Args[0], command)
+		}
 
-	// First, we will create a housing directory for all our files.
-	err = os.Mkdir("votes", 0755)
-	if !os.IsExist(err) {
-		checkError(err)
+		fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	if fileType == Normal {
-		// voting.bin requires all questions and all applicable results.
-		PrepareNationalQuestions()
-		PrepareWorldWideQuestion()
+	options := ResultOptions{MinimumVoters: uint32(*minimumVoters), Quarantine: *quarantine}
 
-		PrepareWorldWideResults()
-	} else if fileType == Results {
-		// National results will generate themselves
-		if locality == Worldwide {
-			PrepareWorldWideResults()
-		}
-	} else if fileType == _Question {
-		if locality == Worldwide {
-			PrepareWorldWideQuestion()
-		} else {
-			PrepareNationalQuestions()
-		}
-	}
+	err := SetupLogger(*logFormat, *logLevel)
+	checkError(err)
 
-	for _, countryCode := range countryCodes {
-		// NOTE: Usually for bulk files, I want to use sync.WaitGroup.
-		// However, it seems that the amount of files we generate for this
-		// will not give us faster speeds, in fact the opposite has occurred with deadlocks at unknown positions.
-		Generate(countryCode)
+	// Editing the position data needs neither the key nor the database.
+	if flag.Arg(0) == "positions" {
+		RunPositions(flag.Args()[1:])
+		return
 	}
-}
-
-func Generate(countryCode uint8) {
-	votes := Votes{}
-	votes.currentCountryCode = countryCode
 
-	// Create the file to write to
-	strCountryCode := ZFill(countryCode, 3)
-	err := os.Mkdir(fmt.Sprintf("votes/%s", strCountryCode), 0755)
-	if !os.IsExist(err) {
-		// If the folder exists we can just continue
+	// These commands only read, so they need neither the key, the output nor the archive.
+	switch flag.Arg(0) {
+	case "preview":
+		RunPreview(flag.Args()[1:])
+		return
+	case "export-results":
+		currentTime, err := ParseTime(*asOf)
+		checkError(err)
+		RunExportResults(flag.Args()[1:], currentTime, options.MinimumVoters)
+		return
+	case "render-maps":
+		currentTime, err := ParseTime(*asOf)
+		checkError(err)
+		countryCodes, err := ParseCountryCodes(*countriesStr)
+		checkError(err)
+		RunRenderMaps(flag.Args()[1:], countryCodes, currentTime, options.MinimumVoters)
+		return
+	case "coverage":
+		currentTime, err := ParseTime(*asOf)
+		checkError(err)
+		layouts, err := GetConfig().Layouts()
+		checkError(err)
+		RunCoverage(flag.Args()[1:], currentTime, layouts)
+		return
+	case "check-text":
+		currentTime, err := ParseTime(*asOf)
 		checkError(err)
+		config := GetConfig()
+		charset, err := config.Charset()
+		checkError(err)
+		layouts, err := config.Layouts()
+		checkError(err)
+		RunCheckText(flag.Args()[1:], currentTime, charset, layouts)
+		return
+	case "translations":
+		currentTime, err := ParseTime(*asOf)
+		checkError(err)
+		config := GetConfig()
+		charset, err := config.Charset()
+		checkError(err)
+		layouts, err := config.Layouts()
+		checkError(err)
+		RunTranslations(flag.Args()[1:], currentTime, charset, layouts, *dryRun)
+		return
+	case "score":
+		currentTime, err := ParseTime(*asOf)
+		checkError(err)
+		RunScore(flag.Args()[1:], currentTime)
+		return
 	}
 
-	buffer := bytes.NewBuffer(nil)
-
-	// Header
-	votes.MakeHeader()
-
-	if fileType == Normal || fileType == _Question {
-		// Questions
-		if len(nationalQuestions) != 0 {
-			votes.MakeNationalQuestionsTable()
-		}
+	key, err := evc.LoadPrivateKey("Private.pem")
+	checkError(err)
 
-		if worldwideQuestion.ID != 0 {
-			votes.MakeWorldWideQuestionsTable()
-		}
+	// A dry run only reads the previous manifest from the output. Rolling back reads the archive even in a dry run.
+	sink, err := OpenSink(*output)
+	checkError(err)
 
-		if worldwideQuestion.ID != 0 || len(nationalQuestions) != 0 {
-			votes.MakeQuestionsTable()
-		}
+	var archive *Archive
+	if *archiveDir != "" && (!*dryRun || flag.Arg(0) == "rollback") {
+		archive, err = OpenArchive(*archiveDir)
+		checkError(err)
 	}
 
-	// National Results
-	if fileType == Normal || fileType == Results {
-		if locality != Worldwide {
-			votes.MakeNationalResultsTable()
-			votes.MakeDetailedNationalResultsTable()
-			votes.MakePositionTable()
+	switch flag.Arg(0) {
+	case "backfill":
+		err = RunBackfill(flag.Args()[1:], key, sink, archive, *workers, options, *dryRun, *partial)
+		if closeErr := sink.Close(); err == nil {
+			err = closeErr
 		}
 
-		if locality != National {
-			votes.MakeWorldWideResultsTable()
-			votes.MakeDetailedWorldWideResults()
-		}
+		checkError(err)
+		return
+	case "rollback":
+		RunRollback(flag.Args()[1:], key, sink, archive, *dryRun)
+		checkError(sink.Close())
+		return
 	}
 
-	if (fileType == Normal || fileType == Results) && locality != National {
-		// Country Table + Text
-		votes.MakeCountryInfoTable()
-		votes.MakeCountryTable()
-	}
+	firstData, err := evc.BuildFirstData(key)
+	checkError(err)
 
-	// Write to byte buffer, add the file size, calculate crc32 then write file
-	votes.WriteAll(buffer)
+	currentTime, err := ParseTime(*asOf)
+	checkError(err)
 
-	crcTable := crc32.MakeTable(crc32.IEEE)
-	checksum := crc32.Checksum(buffer.Bytes()[12:], crcTable)
-	votes.Header.CRC32 = checksum
-	votes.Header.Filesize = uint32(buffer.Len())
+	countryCodes, err := ParseCountryCodes(*countriesStr)
+	checkError(err)
 
-	// Reset the temp buffer and compress
-	buffer.Reset()
-	votes.WriteAll(buffer)
+	fileType := GetFileType(flag.Arg(0))
+	locality := evc.All
+	if flag.NArg() >= 2 {
+		locality = GetLocality(flag.Arg(1))
+	}
 
-	compressed, err := lz11.Compress(buffer.Bytes())
+	ctx := context.Background()
+	pool := ConnectDatabase(ctx)
+	defer pool.Close()
+
+	generator := NewGenerator(ctx, pool, key, fileType, locality, currentTime)
+	generator.dryRun = *dryRun
+	generator.fallbackLongText = *fallbackLongText
+	generator.options = options
+	config := GetConfig()
+	generator.builder.Fallbacks, err = config.FallbackPolicy()
+	checkError(err)
+	generator.charset, err = config.Charset()
+	checkError(err)
+	generator.layouts, err = config.Layouts()
 	checkError(err)
 
-	signed := SignFile(compressed)
+	previous := &Manifest{}
+	if !*full {
+		previous, err = LoadManifest(sink)
+		checkError(err)
+		generator.previous = previous
+	}
 
-	filename := GetFilename(strCountryCode)
+	var publisher *Publisher
+	var extra []*GeneratedFile
+	if *dryRun {
+		logger.Info("planned first data", "path", "first_data.bin", "size", len(firstData))
+	} else {
+		publisher, err = NewPublisher(sink, &key.PublicKey, currentTime)
+		checkError(err)
+		publisher.archive = archive
+		generator.outputDir = publisher.Dir()
+
+		// first_data.bin never changes, so it is only published if missing or forced.
+		if entry, ok := previous.Get("first_data.bin"); !ok || entry.SHA256 != hashFile(firstData) {
+			firstDataFile, err := publisher.Stage("first_data.bin", firstData)
+			checkError(err)
+			extra = append(extra, firstDataFile)
+		}
+	}
 
-	err = os.WriteFile(fmt.Sprintf("votes/%s/%s", strCountryCode, filename), signed, 0666)
-	checkError(err)
-}
+	logger.Info("starting generation", "file_type", fileType, "locality", locality, "time", generator.currentTime, "workers", *workers)
 
-// Write writes the current values in Votes to an io.Writer method.
-// This is required as Go cannot write structs with non-fixed slice sizes,
-// but can write them individually.
-func (v *Votes) Write(writer io.Writer, data interface{}) {
-	err := binary.Write(writer, binary.BigEndian, data)
-	checkError(err)
-}
+	report := NewReport(fileType, locality, generator.currentTime)
+	err = generator.Run(countryCodes, *workers, report)
+	if publisher != nil {
+		if err == nil {
+			err = publisher.Publish(report, extra, *partial)
+			if err != nil {
+				logger.Error("failed to publish", "error", err)
+			}
+		}
 
-func (v *Votes) WriteAll(writer io.Writer) {
-	v.Write(writer, v.Header)
+		if closeErr := publisher.Close(); closeErr != nil {
+			logger.Error("failed to remove staging directory", "error", closeErr)
+		}
 
-	// Questions
-	v.Write(writer, v.NationalQuestionTable)
-	v.Write(writer, v.WorldWideQuestionTable)
-	v.Write(writer, v.QuestionTextInfoTable)
+		if closeErr := sink.Close(); closeErr != nil && err == nil {
+			err = closeErr
+			logger.Error("failed to write output", "output", sink, "error", closeErr)
+		}
 
-	// Go doesn't like nested slices in structs.
-	for _, question := range v.QuestionText {
-		v.Write(writer, question.Question)
-		v.Write(writer, question.Response1)
-		v.Write(writer, question.Response2)
+		// Votes left out of the results are only moved once the files leaving them out are in place.
+		if err == nil {
+			if err = generator.Quarantine(report); err != nil {
+				logger.Error("failed to quarantine votes", "error", err)
+			}
+		}
 	}
 
-	// National Results
-	v.Write(writer, v.NationalResults)
-	v.Write(writer, v.DetailedNationalResults)
-	v.Write(writer, v.PositionEntryTable)
+	report.Finish()
+	report.Print(os.Stdout)
+	if *dryRun {
+		fmt.Printf("\nDry run, nothing was written. First data would be %d bytes.\n", len(firstData))
+		report.PrintPlan(os.Stdout)
+	}
 
-	// Worldwide Results
-	v.Write(writer, v.WorldwideResults)
-	v.Write(writer, v.WorldwideResultsDetailed)
+	if *reportPath != "" {
+		checkError(report.WriteFile(*reportPath))
+	}
 
-	v.Write(writer, v.CountryInfoTable)
-	v.Write(writer, v.CountryTable)
+	if err != nil || report.HasFailures() {
+		pool.Close()
+		os.Exit(1)
+	}
 }
 
-// GetCurrentSize returns the current size of our Votes struct.
-// This is useful for calculating the current offset of Votes.
-func (v *Votes) GetCurrentSize() uint32 {
-	buffer := bytes.NewBuffer([]byte{})
-	v.WriteAll(buffer)
+// ConnectDatabase connects to the database described in config.xml.
+func ConnectDatabase(ctx context.Context) *pgxpool.Pool {
+	// Get config
+	config := GetConfig()
+
+	// Start SQL
+	dbString := fmt.Sprintf("postgres://%s:%s@%s/%s", config.Username, config.Password, config.DatabaseAddress, config.DatabaseName)
+	dbConf, err := pgxpool.ParseConfig(dbString)
+	checkError(err)
+	pool, err := pgxpool.ConnectConfig(ctx, dbConf)
+	checkError(err)
 
-	return uint32(buffer.Len())
+	return pool
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+// ManifestEntry describes a single published file.
+type ManifestEntry struct {
+	Path        string    `json:"path"`
+	Size        int       `json:"size"`
+	SHA256      string    `json:"sha256"`
+	QuestionIDs []uint32  `json:"question_ids,omitempty"`
+	ResultIDs   []uint32  `json:"result_ids,omitempty"`
+	InputHash   string    `json:"input_hash,omitempty"`
+	AsOf        time.Time `json:"as_of"`
+	Generated   time.Time `json:"generated"`
+}
+
+// Manifest lists every file in the published tree.
+type Manifest struct {
+	Updated time.Time       `json:"updated"`
+	Files   []ManifestEntry `json:"files"`
+}
+
+// LoadManifest returns the sink's manifest, or an empty one if it has none.
+func LoadManifest(sink Sink) (*Manifest, error) {
+	manifest := &Manifest{}
+
+	data, err := sink.Get(ManifestName)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, manifest)
+	return manifest, err
+}
+
+// Get returns the entry for the passed path.
+func (m *Manifest) Get(path string) (ManifestEntry, bool) {
+	for _, entry := range m.Files {
+		if entry.Path == path {
+			return entry, true
+		}
+	}
+
+	return ManifestEntry{}, false
+}
+
+// Set adds or replaces the entry for its path, keeping entries sorted.
+func (m *Manifest) Set(entry ManifestEntry) {
+	index := sort.Search(len(m.Files), func(i int) bool {
+		return m.Files[i].Path >= entry.Path
+	})
+
+	if index < len(m.Files) && m.Files[index].Path == entry.Path {
+		m.Files[index] = entry
+		return
+	}
+
+	m.Files = append(m.Files, ManifestEntry{})
+	copy(m.Files[index+1:], m.Files[index:])
+	m.Files[index] = entry
+}
+
+// Marshal returns the manifest as indented JSON.
+func (m *Manifest) Marshal() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
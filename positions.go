@@ -0,0 +1,194 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// positionEdits collects repeated edit flags in the order they were passed.
+type positionEdits []positionEdit
+
+type positionEdit struct {
+	op  string
+	arg string
+}
+
+func (e *positionEdits) flag(op string) flag.Value {
+	return editFlag{edits: e, op: op}
+}
+
+type editFlag struct {
+	edits *positionEdits
+	op    string
+}
+
+func (f editFlag) String() string {
+	return ""
+}
+
+func (f editFlag) Set(value string) error {
+	*f.edits = append(*f.edits, positionEdit{op: f.op, arg: value})
+	return nil
+}
+
+// RunPositions inspects, validates and edits the position data of the results maps.
+func RunPositions(args []string) {
+	if len(args) == 0 {
+		checkError(errors.New("positions requires show, check or edit"))
+	}
+
+	switch args[0] {
+	case "show":
+		countryCode := parsePositionsCountry(args[1:])
+		regions, err := evc.RegionPoints(countryCode)
+		checkError(err)
+
+		data, err := json.MarshalIndent(regions, "", "  ")
+		checkError(err)
+		fmt.Println(string(data))
+	case "check":
+		failed := false
+		for _, countryCode := range evc.CountryCodes {
+			if _, ok := evc.PositionTable[countryCode]; !ok {
+				fmt.Printf("%s: no position data\n", ZFill(countryCode, 3))
+				continue
+			}
+
+			regions, err := evc.RegionPoints(countryCode)
+			if err == nil {
+				err = regions.Validate(countryCode)
+			}
+
+			if err != nil {
+				fmt.Printf("%s: %v\n", ZFill(countryCode, 3), err)
+				failed = true
+			} else {
+				fmt.Printf("%s: ok\n", ZFill(countryCode, 3))
+			}
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	case "edit":
+		runPositionsEdit(args[1:])
+	default:
+		checkError(fmt.Errorf("unknown positions command %q", args[0]))
+	}
+}
+
+func parsePositionsCountry(args []string) uint8 {
+	if len(args) == 0 {
+		checkError(errors.New("a country code is required"))
+	}
+
+	countryCodes, err := ParseCountryCodes(args[0])
+	checkError(err)
+	if len(countryCodes) != 1 {
+		checkError(errors.New("exactly one country code is required"))
+	}
+
+	return countryCodes[0]
+}
+
+func runPositionsEdit(args []string) {
+	var edits positionEdits
+	flags := flag.NewFlagSet("positions edit", flag.ExitOnError)
+	from := flags.String("from", "", "start from a JSON file of points per region instead of the current data")
+	out := flags.String("out", "", "also write the edited points as JSON to this file")
+	flags.Var(edits.flag("set"), "set", "move a point, as region:index=x,y (repeatable)")
+	flags.Var(edits.flag("add"), "add", "add a point to a region, as region=x,y (repeatable)")
+	flags.Var(edits.flag("remove"), "remove", "remove a point, as region:index (repeatable)")
+	countryCode := parsePositionsCountry(args)
+	checkError(flags.Parse(args[1:]))
+
+	var regions evc.PositionMap
+	var err error
+	switch {
+	case *from != "":
+		data, err := os.ReadFile(*from)
+		checkError(err)
+		checkError(json.Unmarshal(data, &regions))
+	case evc.PositionData[int(countryCode)] != "":
+		regions, err = evc.RegionPoints(countryCode)
+		checkError(err)
+	default:
+		// Countries without a map start with every region empty.
+		regions = make(evc.PositionMap, evc.NumberOfRegions[countryCode])
+	}
+
+	for _, edit := range edits {
+		checkError(ApplyPositionEdit(regions, edit.op, edit.arg))
+	}
+
+	checkError(regions.Validate(countryCode))
+
+	if *out != "" {
+		data, err := json.MarshalIndent(regions, "", "  ")
+		checkError(err)
+		checkError(os.WriteFile(*out, data, 0666))
+	}
+
+	counts, data := regions.Encode()
+	table := make([]string, len(counts))
+	for i, count := range counts {
+		table[i] = strconv.Itoa(int(count))
+	}
+
+	fmt.Println("Replace the entries for this country in evc/const.go with:")
+	fmt.Printf("\nPositionTable:\n\t%d: {%s},\n", countryCode, strings.Join(table, ", "))
+	fmt.Printf("\nPositionData:\n\t%d: %q,\n", countryCode, data)
+}
+
+// ApplyPositionEdit applies a single edit to a map. Regions are referred to by their region ID,
+// which starts at 2, and points by their index within the region.
+func ApplyPositionEdit(regions evc.PositionMap, op string, arg string) error {
+	target, value, _ := strings.Cut(arg, "=")
+	regionStr, indexStr, hasIndex := strings.Cut(target, ":")
+
+	regionID, err := strconv.Atoi(regionStr)
+	if err != nil || regionID < 2 || regionID-2 >= len(regions) {
+		return fmt.Errorf("%s %s: invalid region %q", op, arg, regionStr)
+	}
+
+	points := regions[regionID-2]
+	index := -1
+	if hasIndex {
+		index, err = strconv.Atoi(indexStr)
+		if err != nil || index < 0 || index >= len(points) {
+			return fmt.Errorf("%s %s: region %d has no point %q", op, arg, regionID, indexStr)
+		}
+	}
+
+	var point evc.Point
+	if op != "remove" {
+		xStr, yStr, _ := strings.Cut(value, ",")
+		x, xErr := strconv.ParseUint(xStr, 10, 8)
+		y, yErr := strconv.ParseUint(yStr, 10, 8)
+		if xErr != nil || yErr != nil {
+			return fmt.Errorf("%s %s: coordinates must be x,y between 0 and 255", op, arg)
+		}
+
+		point = evc.Point{X: uint8(x), Y: uint8(y)}
+	}
+
+	switch {
+	case op == "set" && hasIndex:
+		points[index] = point
+	case op == "add" && !hasIndex:
+		points = append(points, point)
+	case op == "remove" && hasIndex:
+		points = append(points[:index], points[index+1:]...)
+	default:
+		return fmt.Errorf("invalid %s %s", op, arg)
+	}
+
+	regions[regionID-2] = points
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"testing"
+)
+
+func TestApplyPositionEdit(t *testing.T) {
+	regions := evc.PositionMap{{{X: 1, Y: 1}, {X: 2, Y: 2}}, {}}
+
+	edits := []positionEdit{
+		{"set", "2:1=10,20"},
+		{"add", "3=30,40"},
+		{"remove", "2:0"},
+	}
+
+	for _, edit := range edits {
+		if err := ApplyPositionEdit(regions, edit.op, edit.arg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(regions[0]) != 1 || regions[0][0] != (evc.Point{X: 10, Y: 20}) {
+		t.Errorf("unexpected region 2: %+v", regions[0])
+	}
+
+	if len(regions[1]) != 1 || regions[1][0] != (evc.Point{X: 30, Y: 40}) {
+		t.Errorf("unexpected region 3: %+v", regions[1])
+	}
+
+	for _, edit := range []positionEdit{{"set", "4:0=1,1"}, {"set", "2:5=1,1"}, {"add", "2=300,1"}, {"add", "2:0=1,1"}} {
+		if err := ApplyPositionEdit(regions, edit.op, edit.arg); err == nil {
+			t.Errorf("expected %s %s to fail", edit.op, edit.arg)
+		}
+	}
+}
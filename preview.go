@@ -0,0 +1,260 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RunPreview prints a published file as a player would see it.
+// How the channel rounds percentages and orders countries has not been determined, so the preview
+// does not reproduce them: it shows exact percentages and ranks countries by their share of the first response.
+// The signature is only verified if a key is passed, so translators can preview files without the signing key.
+func RunPreview(args []string) {
+	flags := flag.NewFlagSet("preview", flag.ExitOnError)
+	languageStr := flags.String("language", "en", "language to show the file in ("+languageCodes()+")")
+	keyPath := flags.String("key", "", "PEM public or private key to verify the signature with (default skip verification)")
+	checkError(flags.Parse(args))
+
+	if flags.NArg() != 1 {
+		checkError(errors.New("preview requires a single file"))
+	}
+
+	language, err := evc.ParseLanguageCode(*languageStr)
+	checkError(err)
+
+	data, err := os.ReadFile(flags.Arg(0))
+	checkError(err)
+
+	if *keyPath != "" {
+		key, err := evc.LoadPublicKey(*keyPath)
+		checkError(err)
+		data, err = evc.Unpack(data, key)
+		checkError(err)
+	} else {
+		logger.Warn("not verifying the signature as no key was passed")
+		data, err = evc.UnpackUnverified(data)
+		checkError(err)
+	}
+
+	votes, err := evc.Decode(data)
+	checkError(err)
+
+	checkError(RenderPreview(os.Stdout, votes, language))
+}
+
+func languageCodes() string {
+	var codes []string
+	for language := evc.Japanese; language <= evc.FrenchCanadian; language++ {
+		codes = append(codes, language.String())
+	}
+
+	return strings.Join(codes, ", ")
+}
+
+// RenderPreview writes the questions and results of a decoded file in the passed language.
+// The counts in the file are checked against its tables, so a corrupt file is an error rather than a panic.
+func RenderPreview(writer io.Writer, votes *evc.Votes, language evc.LanguageCode) error {
+	texts := map[uint32]previewText{}
+	for _, table := range [][]evc.QuestionInfo{votes.NationalQuestionTable, votes.WorldWideQuestionTable} {
+		for _, info := range table {
+			text, err := questionText(votes, info, language)
+			if err != nil {
+				return err
+			}
+
+			texts[info.PollID] = text
+		}
+	}
+
+	for _, info := range votes.NationalQuestionTable {
+		renderQuestion(writer, "National", info, texts[info.PollID])
+	}
+
+	for _, info := range votes.WorldWideQuestionTable {
+		renderQuestion(writer, "Worldwide", info, texts[info.PollID])
+	}
+
+	for _, result := range votes.NationalResults {
+		text := resultText(texts, result.PollID)
+		fmt.Fprintf(writer, "National results for question %d\n", result.PollID)
+		renderTotals(writer, text, tallyOf(result.MaleVotersResponse1, result.MaleVotersResponse2, result.FemaleVotersResponse1, result.FemaleVotersResponse2),
+			result.PredictorsResponse1, result.PredictorsResponse2, result.ShowVoterNumberFlag != 0)
+
+		if result.ShowDetailedResultsFlag != 0 {
+			start := int(result.StartingNationalResultDetailedNumber)
+			end := start + int(result.NationalResultDetailedNumber)
+			if end > len(votes.DetailedNationalResults) {
+				return fmt.Errorf("national result %d has detailed results %d to %d, but the file has %d", result.PollID, start, end, len(votes.DetailedNationalResults))
+			}
+
+			for i, region := range votes.DetailedNationalResults[start:end] {
+				// Region 1 is the country itself, so the first region is 2.
+				renderShare(writer, fmt.Sprintf("  Region %d", i+2), text, region.VotersResponse1Number, region.VotersResponse2Number)
+			}
+		}
+
+		fmt.Fprintln(writer)
+	}
+
+	for _, result := range votes.WorldwideResults {
+		text := resultText(texts, result.PollID)
+		fmt.Fprintf(writer, "Worldwide results for question %d\n", result.PollID)
+		renderTotals(writer, text, tallyOf(result.MaleVotersResponse1, result.MaleVotersResponse2, result.FemaleVotersResponse1, result.FemaleVotersResponse2),
+			result.PredictorsResponse1, result.PredictorsResponse2, true)
+
+		start := int(result.WorldWideDetailedTableNumber)
+		end := start + int(result.NumberOfWorldWideDetailedTables)
+		if end > len(votes.WorldwideResultsDetailed) {
+			return fmt.Errorf("worldwide result %d has detailed results %d to %d, but the file has %d", result.PollID, start, end, len(votes.WorldwideResultsDetailed))
+		}
+
+		for i, country := range rankCountries(votes.WorldwideResultsDetailed[start:end]) {
+			name := votes.CountryName(int(country.CountryTableNumber) + countryNameIndex(language))
+			renderShare(writer, fmt.Sprintf("  %2d. %s", i+1, name), text,
+				country.MaleVotersResponse1+country.FemaleVotersResponse1, country.MaleVotersResponse2+country.FemaleVotersResponse2)
+		}
+
+		fmt.Fprintln(writer)
+	}
+
+	if len(votes.NationalResults)+len(votes.WorldwideResults) != 0 {
+		fmt.Fprintln(writer, "Percentages are exact to one decimal place and countries are ranked by their share of the first response.")
+		fmt.Fprintln(writer, "The channel's own rounding and order are not reproduced, so the whole percentages on screen may differ by 1.")
+	}
+
+	return nil
+}
+
+type previewText struct {
+	question  string
+	response1 string
+	response2 string
+}
+
+// questionText returns the text of a question in the passed language, or its first language if it has no such text.
+func questionText(votes *evc.Votes, info evc.QuestionInfo, language evc.LanguageCode) (previewText, error) {
+	start := int(info.QuestionTableEntryNumber)
+	end := start + int(info.NumberOfSupportedLanguages)
+	if info.NumberOfSupportedLanguages == 0 || end > len(votes.QuestionTextInfoTable) {
+		return previewText{}, fmt.Errorf("question %d has text entries %d to %d, but the file has %d", info.PollID, start, end, len(votes.QuestionTextInfoTable))
+	}
+
+	index := start
+	for i := start; i < end; i++ {
+		if votes.QuestionTextInfoTable[i].LanguageCode == uint8(language) {
+			index = i
+			break
+		}
+	}
+
+	if index >= len(votes.QuestionText) {
+		return previewText{}, fmt.Errorf("question %d has text entry %d, but the file has %d", info.PollID, index, len(votes.QuestionText))
+	}
+
+	question, response1, response2 := votes.Text(index)
+	return previewText{question: question, response1: response1, response2: response2}, nil
+}
+
+// resultText returns the responses of a result, which are only known if its question is in the same file.
+func resultText(texts map[uint32]previewText, pollID uint32) previewText {
+	if text, ok := texts[pollID]; ok {
+		return text
+	}
+
+	return previewText{response1: "Response 1", response2: "Response 2"}
+}
+
+func renderQuestion(writer io.Writer, kind string, info evc.QuestionInfo, text previewText) {
+	fmt.Fprintf(writer, "%s question %d\n", kind, info.PollID)
+
+	// The text is wrapped when the file is made, so this is how the channel lays it out.
+	for _, line := range strings.Split(text.question, "\n") {
+		fmt.Fprintf(writer, "  | %s\n", line)
+	}
+
+	fmt.Fprintf(writer, "  A: %s\n", text.response1)
+	fmt.Fprintf(writer, "  B: %s\n\n", text.response2)
+}
+
+func tallyOf(male1, male2, female1, female2 uint32) evc.Tally {
+	return evc.Tally{MaleResponse1: male1, MaleResponse2: male2, FemaleResponse1: female1, FemaleResponse2: female2}
+}
+
+func renderTotals(writer io.Writer, text previewText, tally evc.Tally, predictors1, predictors2 uint32, showVoters bool) {
+	renderShare(writer, "  All", text, tally.Response1(), tally.Response2())
+	renderShare(writer, "  Male", text, tally.MaleResponse1, tally.MaleResponse2)
+	renderShare(writer, "  Female", text, tally.FemaleResponse1, tally.FemaleResponse2)
+	renderShare(writer, "  Predictions", text, predictors1, predictors2)
+
+	if showVoters {
+		fmt.Fprintf(writer, "  Voters: %d\n", tally.Response1()+tally.Response2())
+	}
+}
+
+func renderShare(writer io.Writer, label string, text previewText, response1, response2 uint32) {
+	percent1, percent2, ok := Percentages(response1, response2)
+	if !ok {
+		fmt.Fprintf(writer, "%s: no votes\n", label)
+		return
+	}
+
+	fmt.Fprintf(writer, "%s: %s %.1f%% / %s %.1f%%\n", label, text.response1, percent1, text.response2, percent2)
+}
+
+// Percentages returns the exact percentages of a pair of responses, which are not rounded as the channel's
+// rounding is unknown. ok is false if nobody voted.
+func Percentages(response1, response2 uint32) (percent1 float64, percent2 float64, ok bool) {
+	total := float64(response1) + float64(response2)
+	if total == 0 {
+		return 0, 0, false
+	}
+
+	return float64(response1) * 100 / total, float64(response2) * 100 / total, true
+}
+
+// rankCountries orders the countries of a worldwide result by how strongly they chose the first response.
+// Countries hidden by the privacy threshold have no votes and are left out.
+func rankCountries(countries []evc.DetailedWorldwideResult) []evc.DetailedWorldwideResult {
+	var ranked []evc.DetailedWorldwideResult
+	for _, country := range countries {
+		if country.MaleVotersResponse1+country.MaleVotersResponse2+country.FemaleVotersResponse1+country.FemaleVotersResponse2 != 0 {
+			ranked = append(ranked, country)
+		}
+	}
+
+	share := func(country evc.DetailedWorldwideResult) float64 {
+		response1 := country.MaleVotersResponse1 + country.FemaleVotersResponse1
+		return float64(response1) / float64(response1+country.MaleVotersResponse2+country.FemaleVotersResponse2)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return share(ranked[i]) > share(ranked[j])
+	})
+
+	return ranked
+}
+
+// countryNameIndex returns the position of a language's name within each country's entries in the CountryInfoTable.
+// Country names are only written in evc.Languages, so Portuguese and Canadian French use the nearest one.
+func countryNameIndex(language evc.LanguageCode) int {
+	switch language {
+	case evc.Portuguese:
+		language = evc.Spanish
+	case evc.FrenchCanadian:
+		language = evc.French
+	}
+
+	for i, code := range evc.Languages {
+		if code == language {
+			return i
+		}
+	}
+
+	return 1
+}
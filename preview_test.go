@@ -0,0 +1,93 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentages(t *testing.T) {
+	for _, test := range []struct {
+		response1, response2 uint32
+		percent1, percent2   string
+	}{
+		{1, 1, "50.0", "50.0"},
+		{1, 2, "33.3", "66.7"},
+		{1, 7, "12.5", "87.5"},
+		{5, 0, "100.0", "0.0"},
+	} {
+		percent1, percent2, ok := Percentages(test.response1, test.response2)
+		if got1, got2 := fmt.Sprintf("%.1f", percent1), fmt.Sprintf("%.1f", percent2); !ok || got1 != test.percent1 || got2 != test.percent2 {
+			t.Errorf("%d/%d: got %s%%/%s%%, expected %s%%/%s%%", test.response1, test.response2, got1, got2, test.percent1, test.percent2)
+		}
+	}
+
+	if _, _, ok := Percentages(0, 0); ok {
+		t.Errorf("expected no percentages without votes")
+	}
+}
+
+func TestRenderPreview(t *testing.T) {
+	question := evc.Question{
+		ID:           3,
+		QuestionText: evc.LocalizedText{English: "Cats or dogs?", FrenchCanadian: "Chats ou chiens ?"},
+		Response1:    evc.LocalizedText{English: "Cats", FrenchCanadian: "Chats"},
+		Response2:    evc.LocalizedText{English: "Dogs", FrenchCanadian: "Chiens"},
+		Time:         time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	results := evc.ResultSet{
+		Worldwide: evc.WorldWideResult{PollID: 3, MaleVotersResponse1: 3, FemaleVotersResponse2: 1, PredictorsResponse1: 1, PredictorsResponse2: 2, NumberOfWorldWideDetailedTables: 3},
+		DetailedWorldwide: []evc.DetailedWorldwideResult{
+			{MaleVotersResponse1: 1, FemaleVotersResponse2: 1, CountryTableNumber: 7},
+			{MaleVotersResponse1: 2, CountryTableNumber: 14},
+			{CountryTableNumber: 21},
+		},
+	}
+
+	// Canada supports English, Spanish and Canadian French, which uses the French country names.
+	builder := evc.Builder{FileType: evc.Normal, Locality: evc.Worldwide, Time: question.Time}
+	votes, err := builder.MakeVotes(evc.QuestionSet{Worldwide: question}, results, 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := evc.Decode(votes.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output strings.Builder
+	if err = RenderPreview(&output, decoded, evc.FrenchCanadian); err != nil {
+		t.Fatal(err)
+	}
+
+	preview := output.String()
+
+	for _, expected := range []string{
+		"  | Chats ou chiens ?\n",
+		"  A: Chats\n",
+		"  All: Chats 75.0% / Chiens 25.0%\n",
+		"  Male: Chats 100.0% / Chiens 0.0%\n",
+		"  Predictions: Chats 33.3% / Chiens 66.7%\n",
+		"   1. Brésil: Chats 100.0% / Chiens 0.0%\n   2. Argentine: Chats 50.0% / Chiens 50.0%\n",
+		"The channel's own rounding and order are not reproduced",
+	} {
+		if !strings.Contains(preview, expected) {
+			t.Errorf("expected %q in the preview:\n%s", expected, preview)
+		}
+	}
+
+	// Countries without votes are not ranked.
+	if strings.Contains(preview, " 3. ") {
+		t.Errorf("a country without votes was ranked:\n%s", preview)
+	}
+
+	decoded.WorldwideResults[0].NumberOfWorldWideDetailedTables = 200
+	if err = RenderPreview(io.Discard, decoded, evc.English); err == nil {
+		t.Errorf("expected a result with more detailed results than the file to be an error")
+	}
+}
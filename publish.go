@@ -0,0 +1,239 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// OutputRoot is the default directory of the published tree served to the channel.
+const OutputRoot = "votes"
+
+// ManifestName is the name of the manifest within the published tree.
+const ManifestName = "manifest.json"
+
+// Publisher generates into a local staging directory, validates every file,
+// then promotes the set to the sink.
+type Publisher struct {
+	sink    Sink
+	staging string
+	key     *rsa.PublicKey
+	asOf    time.Time
+	// archive receives every promoted file if set.
+	archive *Archive
+}
+
+func NewPublisher(sink Sink, key *rsa.PublicKey, asOf time.Time) (*Publisher, error) {
+	staging, err := os.MkdirTemp("", "evc-staging-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Publisher{sink: sink, staging: staging, key: key, asOf: asOf}, nil
+}
+
+// Dir returns the staging directory files should be generated into.
+func (p *Publisher) Dir() string {
+	return p.staging
+}
+
+// Stage writes a file that is not created by a Generator, such as first_data.bin.
+func (p *Publisher) Stage(path string, data []byte) (*GeneratedFile, error) {
+	err := os.WriteFile(filepath.Join(p.staging, path), data, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGeneratedFile(path, data), nil
+}
+
+// Validate checks that the staged copy of a file is intact, correctly signed and for the right country.
+// countryCode is 0 for files which are not for a specific country.
+func (p *Publisher) Validate(file *GeneratedFile, countryCode uint8) error {
+	data, err := os.ReadFile(filepath.Join(p.staging, file.Path))
+	if err != nil {
+		return err
+	}
+
+	if len(data) != file.Size || hashFile(data) != file.SHA256 {
+		return errors.New("staged file does not match the generated file")
+	}
+
+	contents, err := evc.Unpack(data, p.key)
+	if err != nil {
+		return err
+	}
+
+	if countryCode != 0 {
+		// The country code follows the version, size, CRC32 and timestamp.
+		if len(contents) <= 16 || contents[16] != countryCode {
+			return fmt.Errorf("file is not for country %d", countryCode)
+		}
+	}
+
+	return nil
+}
+
+// Publish validates every file in the report along with any extra files, then promotes them.
+// Countries with invalid files are marked as failed. Unless partial is set,
+// nothing is promoted when any country failed.
+func (p *Publisher) Publish(report *Report, extra []*GeneratedFile, partial bool) error {
+	var files []*GeneratedFile
+	for _, file := range extra {
+		if err := p.Validate(file, 0); err != nil {
+			return fmt.Errorf("%s: %w", file.Path, err)
+		}
+
+		files = append(files, file)
+	}
+
+	for _, country := range report.Countries {
+		if country.Status != StatusSucceeded {
+			continue
+		}
+
+		if err := p.Validate(country.File, country.CountryCode); err != nil {
+			logger.Error("staged file is invalid", "country", country.CountryCode, "path", country.File.Path, "error", err)
+			report.Invalidate(country.CountryCode, fmt.Errorf("validation: %w", err))
+			continue
+		}
+
+		files = append(files, country.File)
+	}
+
+	if report.HasFailures() && !partial {
+		return errors.New("not publishing as some countries failed")
+	}
+
+	return p.Promote(files)
+}
+
+// PublishFiles stages, validates and promotes files that were not created by a Generator.
+// Nothing is promoted if any file is invalid.
+func (p *Publisher) PublishFiles(files []*GeneratedFile) error {
+	for _, file := range files {
+		path := filepath.Join(p.staging, file.Path)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(path, file.data, 0666)
+		if err != nil {
+			return err
+		}
+
+		countryCode, _ := countryFromPath(file.Path)
+		if err = p.Validate(file, countryCode); err != nil {
+			return fmt.Errorf("%s: %w", file.Path, err)
+		}
+	}
+
+	return p.Promote(files)
+}
+
+// Promote puts the staged files to the sink, then records them in the manifest.
+// An AtomicSink receives the files and manifest together, and replaces them only once all are stored. Other sinks are given one file at a time
+// with the manifest last, so only the manifest changes atomically and clients may see a mix of
+// old and new files while promoting.
+func (p *Publisher) Promote(files []*GeneratedFile) error {
+	manifest, err := LoadManifest(p.sink)
+	if err != nil {
+		return err
+	}
+
+	generated := time.Now().UTC()
+	var entries []ManifestEntry
+	for _, file := range files {
+		if p.archive != nil {
+			if _, err = p.archive.Store(file.data); err != nil {
+				return fmt.Errorf("archive %s: %w", file.Path, err)
+			}
+		}
+
+		asOf := p.asOf
+		if !file.asOf.IsZero() {
+			asOf = file.asOf
+		}
+
+		entry := ManifestEntry{
+			Path:        file.Path,
+			Size:        file.Size,
+			SHA256:      file.SHA256,
+			QuestionIDs: file.QuestionIDs,
+			ResultIDs:   file.ResultIDs,
+			InputHash:   file.InputHash,
+			AsOf:        asOf.UTC(),
+			Generated:   generated,
+		}
+
+		manifest.Set(entry)
+		entries = append(entries, entry)
+	}
+
+	manifest.Updated = generated
+	data, err := manifest.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if atomic, ok := p.sink.(AtomicSink); ok {
+		// The staged copies were validated against the hash of data.
+		contents := map[string][]byte{ManifestName: data}
+		for _, file := range files {
+			contents[file.Path] = file.data
+		}
+
+		if err = atomic.PutAll(contents); err != nil {
+			return fmt.Errorf("put: %w", err)
+		}
+	} else {
+		for _, file := range files {
+			if err = p.sink.Put(file.Path, file.data); err != nil {
+				return fmt.Errorf("put %s: %w", file.Path, err)
+			}
+
+			logger.Debug("promoted file", "sink", p.sink, "path", file.Path, "sha256", file.SHA256)
+		}
+
+		// The manifest is written last so it never lists a file that is not in place.
+		if err = p.sink.Put(ManifestName, data); err != nil {
+			return fmt.Errorf("put %s: %w", ManifestName, err)
+		}
+	}
+
+	if p.archive != nil {
+		if err = p.archive.Record(entries); err != nil {
+			return fmt.Errorf("archive index: %w", err)
+		}
+	}
+
+	logger.Info("published files", "sink", p.sink, "files", len(files))
+	return nil
+}
+
+// Close removes the staging directory and anything left in it.
+func (p *Publisher) Close() error {
+	return os.RemoveAll(p.staging)
+}
+
+// NewGeneratedFile describes a file and its contents.
+func NewGeneratedFile(path string, data []byte) *GeneratedFile {
+	return &GeneratedFile{
+		Path:   path,
+		Size:   len(data),
+		SHA256: hashFile(data),
+		data:   data,
+	}
+}
+
+func hashFile(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPublish(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstData, err := evc.BuildFirstData(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := filepath.Join(t.TempDir(), "votes")
+	publisher, err := NewPublisher(NewFileSink(root), &key.PublicKey, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := publisher.Stage("first_data.bin", firstData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file with a broken signature must stop the country from being published.
+	broken, err := publisher.Stage("broken.bin", append([]byte{}, firstData[:len(firstData)-1]...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := NewReport(evc.Normal, evc.All, time.Now())
+	report.Succeeded(110, broken)
+
+	if err = publisher.Publish(report, []*GeneratedFile{file}, false); err == nil {
+		t.Fatal("expected publishing to be refused when a country fails validation")
+	}
+
+	if report.Count(StatusFailed) != 1 {
+		t.Errorf("expected the broken file to be marked as failed")
+	}
+
+	if _, err = os.Stat(filepath.Join(root, "first_data.bin")); !os.IsNotExist(err) {
+		t.Errorf("nothing should be promoted when a country fails")
+	}
+
+	if err = publisher.Publish(report, []*GeneratedFile{file}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = publisher.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	published, err := os.ReadFile(filepath.Join(root, "first_data.bin"))
+	if err != nil || len(published) != len(firstData) {
+		t.Errorf("first_data.bin was not promoted")
+	}
+
+	manifest, err := LoadManifest(NewFileSink(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok := manifest.Get("first_data.bin")
+	if !ok || entry.SHA256 != file.SHA256 || entry.Size != len(firstData) {
+		t.Errorf("manifest does not describe first_data.bin: %+v", manifest)
+	}
+
+	if _, ok = manifest.Get("broken.bin"); ok {
+		t.Errorf("manifest must not list files that failed validation")
+	}
+
+	entries, _ := os.ReadDir(root)
+	for _, entry := range entries {
+		if entry.Name() != "first_data.bin" && entry.Name() != ManifestName {
+			t.Errorf("unexpected %s left in the published tree", entry.Name())
+		}
+	}
+
+	if info, err := os.Lstat(root); err != nil || !info.IsDir() {
+		t.Errorf("the published tree should stay a plain directory")
+	}
+
+	if err = NewFileSink(root).PutAll(map[string][]byte{"001/other.bin": {1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if staged, _ := os.ReadDir(NewFileSink(root).stagingDir()); len(staged) != 0 {
+		t.Errorf("expected nothing left in staging, got %d entries", len(staged))
+	}
+
+	if _, err = os.Stat(filepath.Join(root, "first_data.bin")); err != nil {
+		t.Errorf("files not passed to PutAll should be kept: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"fmt"
+)
+
+const (
+	// QuarantineNationalVotes moves the votes for a question from regions the country does not have.
+	// Region 1 is the country as a whole, so its votes are kept.
+	QuarantineNationalVotes = `WITH moved AS (
+                    DELETE FROM votes
+                    WHERE question_id = $1
+                    AND country_id = $2
+                    AND type_cd = $3
+                    AND (region_id < 1 OR region_id >= $4 + 2)
+                    RETURNING *
+                    )
+                    INSERT INTO votes_quarantine SELECT moved.*, $5 FROM moved`
+
+	// QuarantineWorldwideVotes moves the votes for a question from countries which are not supported.
+	QuarantineWorldwideVotes = `WITH moved AS (
+                    DELETE FROM votes
+                    WHERE question_id = $1
+                    AND type_cd = $2
+                    AND country_id <> ALL($3)
+                    RETURNING *
+                    )
+                    INSERT INTO votes_quarantine SELECT moved.*, $4 FROM moved`
+)
+
+// Mismatch describes votes for a question that could not be placed in the detailed results,
+// leaving the total different from the sum of the regions or countries.
+type Mismatch struct {
+	QuestionID int `json:"question_id"`
+	// CountryCode is 0 for worldwide results.
+	CountryCode uint8  `json:"country,omitempty"`
+	Reason      string `json:"reason"`
+	Rows        int    `json:"rows"`
+	Voters      uint32 `json:"voters"`
+	// Difference is the total voters minus the sum of the detailed results and the votes for the country as a whole.
+	Difference  int64 `json:"difference"`
+	Quarantined bool  `json:"quarantined"`
+}
+
+// checkNationalResult records a mismatch if the total of a national result does not reconcile with its regions
+// and the countryVoters who voted for the country as a whole.
+func (g *Generator) checkNationalResult(mismatch Mismatch, result evc.NationalResult, regions []evc.DetailedNationalResult, countryVoters uint32) {
+	regionVoters := countryVoters
+	for _, region := range regions {
+		regionVoters += region.VotersResponse1Number + region.VotersResponse2Number
+	}
+
+	total := result.MaleVotersResponse1 + result.MaleVotersResponse2 + result.FemaleVotersResponse1 + result.FemaleVotersResponse2
+	mismatch.Difference = int64(total) - int64(regionVoters)
+	if mismatch.Rows == 0 && mismatch.Difference == 0 {
+		return
+	}
+
+	g.recordMismatch(mismatch)
+}
+
+// checkWorldwideResult records a mismatch if the worldwide total does not reconcile with its countries.
+func (g *Generator) checkWorldwideResult(mismatch Mismatch) {
+	var countryVoters uint32
+	for _, country := range g.results.DetailedWorldwide {
+		countryVoters += country.MaleVotersResponse1 + country.MaleVotersResponse2 + country.FemaleVotersResponse1 + country.FemaleVotersResponse2
+	}
+
+	result := g.results.Worldwide
+	total := result.MaleVotersResponse1 + result.MaleVotersResponse2 + result.FemaleVotersResponse1 + result.FemaleVotersResponse2
+	mismatch.Difference = int64(total) - int64(countryVoters)
+	if mismatch.Rows == 0 && mismatch.Difference == 0 {
+		return
+	}
+
+	g.recordMismatch(mismatch)
+}
+
+// quarantining reports whether votes which cannot be placed are left out of the results, to be moved to quarantine once published.
+func (g *Generator) quarantining() bool {
+	return g.options.Quarantine && !g.dryRun
+}
+
+// Quarantine moves the votes left out of the published results to quarantine in a single transaction.
+// It must only be called once the files have been published, so a failed run leaves the votes table untouched.
+// National votes are only moved if their country's file was published, and votes from unsupported countries
+// only if every country was, as the worldwide total of any country left unpublished still includes them.
+func (g *Generator) Quarantine(report *Report) error {
+	if !g.quarantining() {
+		return nil
+	}
+
+	tx, err := g.pool.Begin(g.ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(g.ctx)
+
+	var moved []int
+	for i, mismatch := range report.Mismatches {
+		if mismatch.Rows == 0 {
+			continue
+		}
+
+		if mismatch.CountryCode == 0 {
+			if report.HasFailures() {
+				continue
+			}
+
+			countryCodes := make([]int32, len(evc.CountryCodes))
+			for i, code := range evc.CountryCodes {
+				countryCodes[i] = int32(code)
+			}
+
+			_, err = tx.Exec(g.ctx, QuarantineWorldwideVotes, mismatch.QuestionID, int(evc.Vote), countryCodes, mismatch.Reason)
+		} else if report.Published(mismatch.CountryCode) {
+			_, err = tx.Exec(g.ctx, QuarantineNationalVotes, mismatch.QuestionID, int(mismatch.CountryCode), int(evc.Vote),
+				int(evc.NumberOfRegions[mismatch.CountryCode]), mismatch.Reason)
+		} else {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("quarantine question %d country %d: %w", mismatch.QuestionID, mismatch.CountryCode, err)
+		}
+
+		moved = append(moved, i)
+	}
+
+	if err = tx.Commit(g.ctx); err != nil {
+		return fmt.Errorf("quarantine: %w", err)
+	}
+
+	for _, i := range moved {
+		report.Mismatches[i].Quarantined = true
+		g.logger.Info("quarantined votes",
+			"question_id", report.Mismatches[i].QuestionID,
+			"country", report.Mismatches[i].CountryCode,
+			"rows", report.Mismatches[i].Rows,
+		)
+	}
+
+	return nil
+}
+
+func (g *Generator) recordMismatch(mismatch Mismatch) {
+	g.logger.Warn("votes do not reconcile with detailed results",
+		"question_id", mismatch.QuestionID,
+		"country", mismatch.CountryCode,
+		"reason", mismatch.Reason,
+		"rows", mismatch.Rows,
+		"voters", mismatch.Voters,
+		"difference", mismatch.Difference,
+		"quarantining", mismatch.Rows != 0 && g.quarantining(),
+	)
+
+	g.mismatchesMutex.Lock()
+	defer g.mismatchesMutex.Unlock()
+	g.mismatches = append(g.mismatches, mismatch)
+}
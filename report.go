@@ -0,0 +1,281 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CountryStatus is the outcome of generating a country's file.
+type CountryStatus string
+
+const (
+	StatusSucceeded CountryStatus = "succeeded"
+	StatusFailed    CountryStatus = "failed"
+	StatusSkipped   CountryStatus = "skipped"
+)
+
+// CountryReport is the outcome of a single country within a run.
+type CountryReport struct {
+	CountryCode uint8          `json:"country_code"`
+	Status      CountryStatus  `json:"status"`
+	File        *GeneratedFile `json:"file,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+}
+
+// Report summarises a generation run so a partial update is never published by accident.
+type Report struct {
+	FileType  string          `json:"file_type"`
+	Locality  string          `json:"locality"`
+	Time      time.Time       `json:"time"`
+	Started   time.Time       `json:"started"`
+	Finished  time.Time       `json:"finished"`
+	Countries []CountryReport `json:"countries"`
+	// Mismatches are votes that could not be placed in the detailed results.
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+	// MissingTranslations are the questions shown in another language, sorted by question.
+	MissingTranslations []MissingTranslation `json:"missing_translations,omitempty"`
+	// DroppedTranslations are the translations too long to fit, which are shown in another language.
+	DroppedTranslations []string `json:"dropped_translations,omitempty"`
+	// ReplacedCharacters are the characters shown as ? as the font cannot draw them.
+	ReplacedCharacters []string `json:"replaced_characters,omitempty"`
+	// InvalidRows are the votes rows left out of the results as their tallies could not be read.
+	InvalidRows []InvalidRow `json:"invalid_rows,omitempty"`
+}
+
+// InvalidRow counts the votes rows of a question and country which were left out of the results.
+type InvalidRow struct {
+	QuestionID  int   `json:"question_id"`
+	CountryCode uint8 `json:"country"`
+	Rows        int   `json:"rows"`
+	// Error is the reason the first of the rows could not be read.
+	Error string `json:"error"`
+}
+
+// MissingTranslation is a question shown in another language as it has not been translated.
+type MissingTranslation struct {
+	QuestionID int              `json:"question_id"`
+	Language   evc.LanguageCode `json:"language"`
+	Used       evc.LanguageCode `json:"used"`
+	// Fields are the parts of the question which are missing.
+	Fields []string `json:"fields"`
+	// Countries is a list of ints so it is not written as base64.
+	Countries []int `json:"countries"`
+}
+
+func NewReport(fileType evc.FileType, locality evc.Locality, currentTime time.Time) *Report {
+	return &Report{
+		FileType: fileType.String(),
+		Locality: locality.String(),
+		Time:     currentTime,
+		Started:  time.Now(),
+	}
+}
+
+func (r *Report) Succeeded(countryCode uint8, file *GeneratedFile) {
+	r.Countries = append(r.Countries, CountryReport{CountryCode: countryCode, Status: StatusSucceeded, File: file})
+}
+
+func (r *Report) Failed(countryCode uint8, err error) {
+	r.Countries = append(r.Countries, CountryReport{CountryCode: countryCode, Status: StatusFailed, Reason: err.Error()})
+}
+
+// Invalidate marks a country that succeeded as failed.
+func (r *Report) Invalidate(countryCode uint8, err error) {
+	for i, country := range r.Countries {
+		if country.CountryCode == countryCode {
+			r.Countries[i] = CountryReport{CountryCode: countryCode, Status: StatusFailed, Reason: err.Error()}
+		}
+	}
+}
+
+// Published reports whether the country's file was published, which is only known once Publisher.Publish has returned.
+func (r *Report) Published(countryCode uint8) bool {
+	for _, country := range r.Countries {
+		if country.CountryCode == countryCode {
+			return country.Status == StatusSucceeded
+		}
+	}
+
+	return false
+}
+
+func (r *Report) Skipped(countryCode uint8, reason string) {
+	r.Countries = append(r.Countries, CountryReport{CountryCode: countryCode, Status: StatusSkipped, Reason: reason})
+}
+
+// AddFallbacks groups the fallbacks used when writing question text by question and language.
+func (r *Report) AddFallbacks(fallbacks []evc.Fallback) {
+	for _, fallback := range fallbacks {
+		index := slices.IndexFunc(r.MissingTranslations, func(missing MissingTranslation) bool {
+			return missing.QuestionID == fallback.QuestionID && missing.Language == fallback.Language && missing.Used == fallback.Used
+		})
+
+		if index == -1 {
+			r.MissingTranslations = append(r.MissingTranslations, MissingTranslation{
+				QuestionID: fallback.QuestionID,
+				Language:   fallback.Language,
+				Used:       fallback.Used,
+			})
+			index = len(r.MissingTranslations) - 1
+		}
+
+		missing := &r.MissingTranslations[index]
+		if !slices.Contains(missing.Fields, fallback.Field) {
+			missing.Fields = append(missing.Fields, fallback.Field)
+			slices.Sort(missing.Fields)
+		}
+
+		if !slices.Contains(missing.Countries, int(fallback.CountryCode)) {
+			missing.Countries = append(missing.Countries, int(fallback.CountryCode))
+			slices.Sort(missing.Countries)
+		}
+	}
+
+	slices.SortFunc(r.MissingTranslations, func(a, b MissingTranslation) int {
+		if a.QuestionID != b.QuestionID {
+			return a.QuestionID - b.QuestionID
+		}
+
+		return int(a.Language) - int(b.Language)
+	})
+}
+
+// AddMismatches adds the votes which could not be placed, sorted by question and country
+// as they are recorded in the order countries finish.
+func (r *Report) AddMismatches(mismatches []Mismatch) {
+	r.Mismatches = append(r.Mismatches, mismatches...)
+	slices.SortFunc(r.Mismatches, func(a, b Mismatch) int {
+		if a.QuestionID != b.QuestionID {
+			return a.QuestionID - b.QuestionID
+		}
+
+		return int(a.CountryCode) - int(b.CountryCode)
+	})
+}
+
+// AddInvalidRows adds the rows which could not be read, sorted by question and country.
+func (r *Report) AddInvalidRows(rows []InvalidRow) {
+	r.InvalidRows = append(r.InvalidRows, rows...)
+	slices.SortFunc(r.InvalidRows, func(a, b InvalidRow) int {
+		if a.QuestionID != b.QuestionID {
+			return a.QuestionID - b.QuestionID
+		}
+
+		return int(a.CountryCode) - int(b.CountryCode)
+	})
+}
+
+func (r *Report) Finish() {
+	r.Finished = time.Now()
+}
+
+// Count returns the number of countries with the given status.
+func (r *Report) Count(status CountryStatus) int {
+	count := 0
+	for _, country := range r.Countries {
+		if country.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// HasFailures reports whether any country failed.
+func (r *Report) HasFailures() bool {
+	return r.Count(StatusFailed) != 0
+}
+
+// Print writes a human-readable summary of the run.
+func (r *Report) Print(writer io.Writer) {
+	fmt.Fprintf(writer, "%s %s run for %s: %d succeeded, %d failed, %d skipped (%s)\n",
+		r.Locality, r.FileType, r.Time.Format(time.RFC3339),
+		r.Count(StatusSucceeded), r.Count(StatusFailed), r.Count(StatusSkipped),
+		r.Finished.Sub(r.Started).Round(time.Millisecond),
+	)
+
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	for _, country := range r.Countries {
+		if country.Status == StatusSucceeded {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", ZFill(country.CountryCode, 3), country.Status, country.File.Path)
+		} else {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", ZFill(country.CountryCode, 3), country.Status, country.Reason)
+		}
+	}
+
+	table.Flush()
+
+	for _, missing := range r.MissingTranslations {
+		countries := make([]string, len(missing.Countries))
+		for i, countryCode := range missing.Countries {
+			countries[i] = ZFill(uint8(countryCode), 3)
+		}
+
+		fmt.Fprintf(writer, "question %d is missing %s text for %s (shown in %s) in %s\n",
+			missing.QuestionID, missing.Language, strings.Join(missing.Fields, ", "), missing.Used, strings.Join(countries, ", "),
+		)
+	}
+
+	for _, dropped := range r.DroppedTranslations {
+		fmt.Fprintf(writer, "%s, shown in another language\n", dropped)
+	}
+
+	for _, replaced := range r.ReplacedCharacters {
+		fmt.Fprintf(writer, "%s, shown as ?\n", replaced)
+	}
+
+	for _, row := range r.InvalidRows {
+		fmt.Fprintf(writer, "question %d country %s: %d votes rows left out as they cannot be read (%s)\n",
+			row.QuestionID, ZFill(row.CountryCode, 3), row.Rows, row.Error,
+		)
+	}
+
+	for _, mismatch := range r.Mismatches {
+		action := "counted in the total only"
+		if mismatch.Quarantined {
+			action = "quarantined"
+		}
+
+		fmt.Fprintf(writer, "question %d country %s: %d rows with %s (%d voters, %s), total differs from detailed results by %d\n",
+			mismatch.QuestionID, ZFill(mismatch.CountryCode, 3), mismatch.Rows, mismatch.Reason, mismatch.Voters, action, mismatch.Difference,
+		)
+	}
+}
+
+// PrintPlan writes what each succeeded country would have written in a dry run.
+func (r *Report) PrintPlan(writer io.Writer) {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "country\tfilename\tquestions\tresults\tnational q/r/detailed/positions\tworldwide q/r/detailed\ttexts\tcountries\tsize")
+	for _, country := range r.Countries {
+		if country.Status != StatusSucceeded {
+			continue
+		}
+
+		header := country.File.Header
+		fmt.Fprintf(table, "%s\t%s\t%v\t%v\t%d/%d/%d/%d\t%d/%d/%d\t%d\t%d\t%d\n",
+			ZFill(country.CountryCode, 3), country.File.Path, country.File.QuestionIDs, country.File.ResultIDs,
+			header.NumberOfNationalQuestions, header.NumberOfNationalResults, header.NumberOfDetailedNationalResults, header.NumberOfPositionTables,
+			header.NumberOfWorldWideQuestions, header.NumberOfWorldWideResults, header.NumberOfDetailedWorldWideResults,
+			header.NumberOfQuestions, header.NumberOfCountries, country.File.Size,
+		)
+	}
+
+	table.Flush()
+}
+
+// WriteFile writes the report as JSON.
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0666)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Colours of the results map. They only tell the responses apart and have not been compared with the channel's.
+const (
+	response1Colour = "#e4572e"
+	response2Colour = "#2e86de"
+	noVotesColour   = "#c8c8c8"
+)
+
+// RenderResultsMap writes an SVG map of a national result, with a marker at each of a region's points
+// coloured by the split between the responses in that region.
+func RenderResultsMap(writer io.Writer, countryCode uint8, question evc.Question, result evc.NationalResult, regions []evc.DetailedNationalResult) error {
+	points, err := evc.RegionPoints(countryCode)
+	if err != nil {
+		return err
+	}
+
+	if len(regions) != len(points) {
+		return fmt.Errorf("country %d has %d regions but position data for %d", countryCode, len(regions), len(points))
+	}
+
+	language := evc.English
+	if languages := evc.GetSupportedLanguages(countryCode); len(languages) != 0 {
+		language = languages[0]
+	}
+
+	// Positions are a byte each, so the map is 256 by 256 with room below for the legend.
+	fmt.Fprintln(writer, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 256 300" font-family="sans-serif" font-size="8">`)
+	fmt.Fprintf(writer, "  <title>%s</title>\n", html.EscapeString(question.QuestionText.Get(language)))
+	fmt.Fprintln(writer, `  <rect width="256" height="256" fill="#f4f4f4"/>`)
+
+	for i, region := range regions {
+		colour := regionColour(region)
+		for _, point := range points[i] {
+			fmt.Fprintf(writer, "  <circle cx=\"%d\" cy=\"%d\" r=\"4\" fill=\"%s\" stroke=\"#ffffff\"><title>Region %d: %d / %d</title></circle>\n",
+				point.X, point.Y, colour, i+2, region.VotersResponse1Number, region.VotersResponse2Number)
+		}
+	}
+
+	total1 := result.MaleVotersResponse1 + result.FemaleVotersResponse1
+	total2 := result.MaleVotersResponse2 + result.FemaleVotersResponse2
+	fmt.Fprintf(writer, "  <circle cx=\"8\" cy=\"270\" r=\"4\" fill=\"%s\"/><text x=\"16\" y=\"273\">%s (%d)</text>\n",
+		response1Colour, html.EscapeString(question.Response1.Get(language)), total1)
+	fmt.Fprintf(writer, "  <circle cx=\"8\" cy=\"288\" r=\"4\" fill=\"%s\"/><text x=\"16\" y=\"291\">%s (%d)</text>\n",
+		response2Colour, html.EscapeString(question.Response2.Get(language)), total2)
+	_, err = fmt.Fprintln(writer, "</svg>")
+	return err
+}
+
+// regionColour blends the response colours by the region's split, or is grey if nobody in the region voted.
+func regionColour(region evc.DetailedNationalResult) string {
+	total := region.VotersResponse1Number + region.VotersResponse2Number
+	if total == 0 {
+		return noVotesColour
+	}
+
+	share := float64(region.VotersResponse1Number) / float64(total)
+	var r1, g1, b1, r2, g2, b2 int
+	fmt.Sscanf(response1Colour, "#%02x%02x%02x", &r1, &g1, &b1)
+	fmt.Sscanf(response2Colour, "#%02x%02x%02x", &r2, &g2, &b2)
+
+	blend := func(a, b int) int {
+		return int(float64(a)*share + float64(b)*(1-share) + 0.5)
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", blend(r1, r2), blend(g1, g2), blend(b1, b2))
+}
+
+// RunRenderMaps renders a results map for each country with position data and each of its applicable national results.
+func RunRenderMaps(args []string, countryCodes []uint8, currentTime time.Time, minimumVoters uint32) {
+	flags := flag.NewFlagSet("render-maps", flag.ExitOnError)
+	out := flags.String("out", "maps", "directory to write the maps to")
+	checkError(flags.Parse(args))
+
+	ctx := context.Background()
+	pool := ConnectDatabase(ctx)
+	defer pool.Close()
+
+	// Rendering never quarantines votes, as it only reads them.
+	generator := NewGenerator(ctx, pool, nil, evc.Normal, evc.National, currentTime)
+	generator.options = ResultOptions{MinimumVoters: minimumVoters}
+
+	failed := false
+	rendered := 0
+	for _, countryCode := range countryCodes {
+		logger := generator.logger.With("country", countryCode)
+		if _, ok := evc.PositionTable[countryCode]; !ok {
+			logger.Debug("country has no position table")
+			continue
+		}
+
+		results, detailed, err := generator.PrepareNationalResults(countryCode, logger)
+		if err != nil {
+			// Keep going so the other countries are still rendered.
+			logger.Error("failed to prepare national results", "error", err)
+			failed = true
+			continue
+		}
+
+		for i, result := range results {
+			evc.ApplyNationalThreshold(&result, detailed[i], minimumVoters)
+			question, err := scanQuestion(pool.QueryRow(ctx, QueryQuestion, result.PollID))
+			checkError(err)
+
+			path := filepath.Join(*out, ZFill(countryCode, 3), fmt.Sprintf("%d.svg", result.PollID))
+			err = writeResultsMap(path, countryCode, question, result, detailed[i])
+			if err != nil {
+				// Keep going so every country with broken position data is reported at once.
+				logger.Error("failed to render map", "question_id", result.PollID, "error", err)
+				failed = true
+				continue
+			}
+
+			rendered++
+		}
+	}
+
+	fmt.Printf("Rendered %d maps to %s.\n", rendered, *out)
+	if failed {
+		checkError(errors.New("some maps could not be rendered"))
+	}
+}
+
+func writeResultsMap(path string, countryCode uint8, question evc.Question, result evc.NationalResult, regions []evc.DetailedNationalResult) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = RenderResultsMap(file, countryCode, question, result, regions)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(path)
+	}
+
+	return err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderResultsMap(t *testing.T) {
+	question := evc.Question{
+		ID:           3,
+		QuestionText: evc.LocalizedText{English: "Tea & coffee?"},
+		Response1:    evc.LocalizedText{English: "Tea"},
+		Response2:    evc.LocalizedText{English: "Coffee"},
+	}
+
+	result := evc.NationalResult{PollID: 3, MaleVotersResponse1: 10, FemaleVotersResponse2: 10}
+	regions := []evc.DetailedNationalResult{
+		{VotersResponse1Number: 10},
+		{VotersResponse2Number: 10},
+		{},
+		{},
+		{},
+	}
+
+	var buffer bytes.Buffer
+	if err := RenderResultsMap(&buffer, 110, question, result, regions); err != nil {
+		t.Fatal(err)
+	}
+
+	svg := buffer.String()
+
+	// The United Kingdom has 7 points across its 5 regions, plus a marker for each response in the legend.
+	if count := strings.Count(svg, "<circle"); count != 9 {
+		t.Errorf("expected 9 circles, got %d", count)
+	}
+
+	if !strings.Contains(svg, `cx="180" cy="180" r="4" fill="`+response1Colour) {
+		t.Errorf("the first region should be entirely response 1")
+	}
+
+	if !strings.Contains(svg, "Tea &amp; coffee?") {
+		t.Errorf("question text was not escaped")
+	}
+
+	// The last three regions have 4 points between them.
+	if strings.Count(svg, noVotesColour) != 4 {
+		t.Errorf("regions without votes should be grey")
+	}
+
+	if err := RenderResultsMap(&buffer, 110, question, result, regions[:4]); err == nil {
+		t.Errorf("expected a mismatched number of regions to fail")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/rsa"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RunRollback republishes the files that were live at an earlier time from the archive.
+func RunRollback(args []string, key *rsa.PrivateKey, sink Sink, archive *Archive, dryRun bool) {
+	flags := flag.NewFlagSet("rollback", flag.ExitOnError)
+	toStr := flags.String("to", "", "restore the files published at this date (YYYY-MM-DD) or time (RFC 3339)")
+	countriesStr := flags.String("countries", "", "comma separated country codes to roll back (default all)")
+	checkError(flags.Parse(args))
+
+	if *toStr == "" {
+		checkError(errors.New("rollback requires --to"))
+	}
+
+	if archive == nil {
+		checkError(errors.New("rollback requires an archive"))
+	}
+
+	to, err := ParseTime(*toStr)
+	checkError(err)
+
+	countryCodes, err := ParseCountryCodes(*countriesStr)
+	checkError(err)
+
+	entries, err := archive.AsOf(to)
+	checkError(err)
+
+	manifest, err := LoadManifest(sink)
+	checkError(err)
+
+	var files []*GeneratedFile
+	for _, entry := range entries {
+		countryCode, ok := countryFromPath(entry.Path)
+		if ok && !containsCountry(countryCodes, countryCode) {
+			continue
+		} else if !ok && *countriesStr != "" {
+			// Files that are not for a country, such as first_data.bin, are only restored in full rollbacks.
+			continue
+		}
+
+		if current, ok := manifest.Get(entry.Path); ok && current.SHA256 == entry.SHA256 {
+			continue
+		}
+
+		fmt.Printf("%s: restoring %s from %s\n", entry.Path, entry.SHA256[:12], entry.Generated.Format("2006-01-02 15:04:05"))
+		if dryRun {
+			continue
+		}
+
+		data, err := archive.Load(entry.SHA256)
+		checkError(err)
+
+		file := NewGeneratedFile(entry.Path, data)
+		file.QuestionIDs = entry.QuestionIDs
+		file.ResultIDs = entry.ResultIDs
+		file.InputHash = entry.InputHash
+		file.asOf = entry.AsOf
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		fmt.Println("Nothing to roll back.")
+		return
+	}
+
+	publisher, err := NewPublisher(sink, &key.PublicKey, to)
+	checkError(err)
+	publisher.archive = archive
+
+	err = publisher.PublishFiles(files)
+	if closeErr := publisher.Close(); closeErr != nil {
+		logger.Error("failed to remove staging directory", "error", closeErr)
+	}
+
+	checkError(err)
+	fmt.Printf("Rolled back %d files to %s.\n", len(files), to.Format("2006-01-02 15:04:05"))
+}
+
+// countryFromPath returns the country a published file belongs to.
+func countryFromPath(path string) (uint8, bool) {
+	dir, _, found := strings.Cut(path, "/")
+	if !found {
+		return 0, false
+	}
+
+	code, err := strconv.ParseUint(dir, 10, 8)
+	return uint8(code), err == nil
+}
+
+func containsCountry(countryCodes []uint8, countryCode uint8) bool {
+	for _, code := range countryCodes {
+		if code == countryCode {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Sink stores files in a bucket of S3-compatible storage such as MinIO.
+// Requests use path-style addressing and are signed with AWS Signature Version 4.
+type S3Sink struct {
+	endpoint *url.URL
+	bucket   string
+	prefix   string
+	config   S3Config
+	client   *http.Client
+}
+
+func NewS3Sink(config S3Config, bucket string, prefix string) (*S3Sink, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return &S3Sink{
+		endpoint: endpoint,
+		bucket:   bucket,
+		prefix:   strings.Trim(prefix, "/"),
+		config:   config,
+		client:   &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+func (s *S3Sink) objectURL(key string) *url.URL {
+	objectURL := *s.endpoint
+	objectURL.Path = path.Join("/", s.endpoint.Path, s.bucket, s.prefix, key)
+	return &objectURL
+}
+
+func (s *S3Sink) do(method string, key string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequest(method, s.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	signV4(request, body, time.Now(), s.config)
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, fmt.Errorf("%s: %w", key, os.ErrNotExist)
+	} else if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		response.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s: %s", method, key, response.Status, message)
+	}
+
+	return response, nil
+}
+
+func (s *S3Sink) Put(key string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+
+	response, err := s.do(http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+
+	return response.Body.Close()
+}
+
+func (s *S3Sink) Get(key string) ([]byte, error) {
+	response, err := s.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	return io.ReadAll(response.Body)
+}
+
+func (s *S3Sink) Exists(key string) (bool, error) {
+	response, err := s.do(http.MethodHead, key, nil)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, response.Body.Close()
+}
+
+func (s *S3Sink) Close() error {
+	return nil
+}
+
+func (s *S3Sink) String() string {
+	return "s3://" + path.Join(s.bucket, s.prefix)
+}
+
+// signV4 adds the headers and Authorization for AWS Signature Version 4,
+// signing the host and every header already set on the request.
+func signV4(request *http.Request, body []byte, now time.Time, config S3Config) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := hashFile(body)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		uriEncode(request.URL.Path, false),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := now.Format("20060102") + "/" + config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashFile([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and slashes unless encodeSlash is set.
+func uriEncode(str string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, c := range []byte(str) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
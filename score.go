@@ -0,0 +1,190 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// QueryClosedQuestions queries the questions whose results have been published, in the order they closed.
+	QueryClosedQuestions = `SELECT question_id, type FROM questions
+							WHERE (type = 'n' AND date <= $1)
+							OR (type = 'w' AND date <= $2)
+							ORDER BY date, question_id`
+
+	// QueryQuestionVotes queries every vote and prediction for a question, along with the Wii number of the console that sent it.
+	// wii_no is added by sql/005_votes_wii_no.sql.
+	QueryQuestionVotes = `SELECT type_cd, wii_no, country_id, region_id, ` + tallyColumns + ` FROM votes
+                    WHERE question_id = $1`
+)
+
+// RunScore scores every prediction made for questions which closed before currentTime,
+// then writes the leaderboards and per-region statistics.
+// Only predictions recorded with a Wii number can be scored, which are those made since sql/005_votes_wii_no.sql
+// was applied. Older predictions are counted and left out, so polls from before it have no scores.
+func RunScore(args []string, currentTime time.Time) {
+	flags := flag.NewFlagSet("score", flag.ExitOnError)
+	out := flags.String("out", "leaderboard", "directory to write the leaderboards and region statistics to")
+	format := flags.String("format", "json", "output format (csv or json)")
+	top := flags.Int("top", 100, "number of consoles on each leaderboard")
+	minimumPredictions := flags.Int("min-predictions", 10, "fewest scored predictions a console needs to be ranked")
+	checkError(flags.Parse(args))
+
+	ctx := context.Background()
+	pool := ConnectDatabase(ctx)
+	defer pool.Close()
+
+	scorer, summary, err := ScorePredictions(ctx, pool, currentTime)
+	checkError(err)
+
+	header := []string{"rank", "console_id", "country", "region", "predictions", "correct", "accuracy", "current_streak", "best_streak"}
+	writeLeaderboard := func(name string, countryCode uint8) {
+		leaderboard := scorer.Leaderboard(countryCode, *minimumPredictions, *top)
+		rank := 0
+		path := filepath.Join(*out, name+"."+*format)
+		checkError(WriteExport(path, *format, leaderboard, header, func(score PredictorScore) []string {
+			rank++
+			return []string{
+				strconv.Itoa(rank), strconv.FormatInt(score.ConsoleID, 10), ZFill(score.CountryCode, 3), strconv.Itoa(score.RegionID),
+				strconv.Itoa(score.Predictions), strconv.Itoa(score.Correct), strconv.FormatFloat(score.Accuracy, 'f', 4, 64),
+				strconv.Itoa(score.CurrentStreak), strconv.Itoa(score.BestStreak),
+			}
+		}))
+	}
+
+	writeLeaderboard("worldwide", 0)
+	for _, countryCode := range evc.CountryCodes {
+		writeLeaderboard(ZFill(countryCode, 3), countryCode)
+	}
+
+	regionHeader := []string{"country", "region", "predictors", "predictions", "correct", "accuracy"}
+	checkError(WriteExport(filepath.Join(*out, "regions."+*format), *format, scorer.Regions(), regionHeader, func(region RegionStats) []string {
+		return []string{
+			ZFill(region.CountryCode, 3), strconv.Itoa(region.RegionID), strconv.Itoa(region.Predictors),
+			strconv.Itoa(region.Predictions), strconv.Itoa(region.Correct), strconv.FormatFloat(region.Accuracy, 'f', 4, 64),
+		}
+	}))
+
+	fmt.Printf("Scored %d questions, leaderboards written to %s.\n", summary.Questions, *out)
+	if summary.UnattributedPredictions != 0 {
+		fmt.Printf("%d predictions were not scored as they have no Wii number, which is only recorded since sql/005_votes_wii_no.sql.\n",
+			summary.UnattributedPredictions)
+	}
+
+	if summary.InvalidRows != 0 {
+		fmt.Printf("%d votes rows were left out as they cannot be read.\n", summary.InvalidRows)
+	}
+}
+
+// ScoreSummary counts what ScorePredictions scored, and what it had to leave out.
+type ScoreSummary struct {
+	Questions int
+	// InvalidRows are votes rows whose tallies could not be read.
+	InvalidRows int
+	// UnattributedPredictions are predictions without a Wii number, which cannot be scored.
+	UnattributedPredictions int
+}
+
+// ScorePredictions scores the predictions for every question closed before currentTime.
+func ScorePredictions(ctx context.Context, pool *pgxpool.Pool, currentTime time.Time) (*Scorer, ScoreSummary, error) {
+	// National questions close after 7 days and worldwide questions after 15.
+	rows, err := pool.Query(ctx, QueryClosedQuestions, currentTime.AddDate(0, 0, -7), currentTime.AddDate(0, 0, -15))
+	if err != nil {
+		return nil, ScoreSummary{}, err
+	}
+
+	var questions []ClosedQuestion
+	for rows.Next() {
+		var question ClosedQuestion
+		var questionType string
+		if err = rows.Scan(&question.ID, &questionType); err != nil {
+			rows.Close()
+			return nil, ScoreSummary{}, err
+		}
+
+		question.Worldwide = questionType == "w"
+		questions = append(questions, question)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, ScoreSummary{}, err
+	}
+
+	scorer := NewScorer()
+	summary := ScoreSummary{Questions: len(questions)}
+	for _, question := range questions {
+		predictions, err := loadQuestionVotes(ctx, pool, &question, &summary)
+		if err != nil {
+			return nil, ScoreSummary{}, fmt.Errorf("question %d: %w", question.ID, err)
+		}
+
+		scorer.Score(question, predictions)
+		logger.Debug("scored question", "question_id", question.ID, "predictions", len(predictions))
+	}
+
+	return scorer, summary, nil
+}
+
+// loadQuestionVotes tallies the votes for a question by country and returns its predictions.
+// Rows which cannot be read are logged, counted in summary and left out.
+func loadQuestionVotes(ctx context.Context, pool *pgxpool.Pool, question *ClosedQuestion, summary *ScoreSummary) ([]PredictionRecord, error) {
+	rows, err := pool.Query(ctx, QueryQuestionVotes, question.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	question.Votes = map[uint8]evc.Tally{}
+	var predictions []PredictionRecord
+	for rows.Next() {
+		var typeCD evc.VoteType
+		var consoleID *int64
+		var countryID int
+		var regionID int
+		var row tallyRow
+
+		err = rows.Scan(append([]any{&typeCD, &consoleID, &countryID, &regionID}, row.targets()...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		tally, err := row.Tally(typeCD)
+		if err != nil {
+			logger.Warn("skipping a votes row that cannot be read", "question_id", question.ID, "country", countryID, "error", err)
+			summary.InvalidRows++
+			continue
+		}
+
+		if typeCD == evc.Vote {
+			question.Votes[uint8(countryID)] = question.Votes[uint8(countryID)].Add(tally)
+			continue
+		}
+
+		// Predictions cannot be scored without knowing which console made them.
+		if consoleID == nil {
+			summary.UnattributedPredictions += int(tally.PredictorsResponse1 + tally.PredictorsResponse2)
+			continue
+		}
+
+		// A console predicts a single response, so rows predicting both or neither are not from one console.
+		prediction := PredictionRecord{ConsoleID: *consoleID, CountryCode: uint8(countryID), RegionID: regionID}
+		if tally.PredictorsResponse1 != 0 && tally.PredictorsResponse2 == 0 {
+			prediction.Response = 1
+		} else if tally.PredictorsResponse2 != 0 && tally.PredictorsResponse1 == 0 {
+			prediction.Response = 2
+		} else {
+			continue
+		}
+
+		predictions = append(predictions, prediction)
+	}
+
+	return predictions, rows.Err()
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"sort"
+)
+
+// PredictionRecord is a single console's prediction for a question.
+type PredictionRecord struct {
+	ConsoleID   int64
+	CountryCode uint8
+	RegionID    int
+	// Response is 1 or 2.
+	Response int
+}
+
+// ClosedQuestion is a question whose voting has ended, along with the votes cast in each country.
+type ClosedQuestion struct {
+	ID        int
+	Worldwide bool
+	Votes     map[uint8]evc.Tally
+}
+
+// Majority returns the response most voted for in the country, or worldwide if the question is worldwide.
+// It returns 0 if the responses are tied.
+func (q ClosedQuestion) Majority(countryCode uint8) int {
+	var response1, response2 uint32
+	for code, tally := range q.Votes {
+		if q.Worldwide || code == countryCode {
+			response1 += tally.Response1()
+			response2 += tally.Response2()
+		}
+	}
+
+	switch {
+	case response1 > response2:
+		return 1
+	case response2 > response1:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// PredictorScore is the prediction record of a single console.
+type PredictorScore struct {
+	ConsoleID int64 `json:"console_id"`
+	// CountryCode and RegionID are where the console last predicted from.
+	CountryCode   uint8   `json:"country"`
+	RegionID      int     `json:"region"`
+	Predictions   int     `json:"predictions"`
+	Correct       int     `json:"correct"`
+	Accuracy      float64 `json:"accuracy"`
+	CurrentStreak int     `json:"current_streak"`
+	BestStreak    int     `json:"best_streak"`
+}
+
+// RegionStats summarises the predictions made from a region.
+type RegionStats struct {
+	CountryCode uint8   `json:"country"`
+	RegionID    int     `json:"region"`
+	Predictors  int     `json:"predictors"`
+	Predictions int     `json:"predictions"`
+	Correct     int     `json:"correct"`
+	Accuracy    float64 `json:"accuracy"`
+}
+
+type regionKey struct {
+	countryCode uint8
+	regionID    int
+}
+
+// Scorer compares predictions with the final majority of each question.
+// Streaks depend on order, so questions must be scored in the order they closed.
+type Scorer struct {
+	consoles   map[int64]*PredictorScore
+	regions    map[regionKey]*RegionStats
+	predictors map[regionKey]map[int64]bool
+}
+
+func NewScorer() *Scorer {
+	return &Scorer{
+		consoles:   map[int64]*PredictorScore{},
+		regions:    map[regionKey]*RegionStats{},
+		predictors: map[regionKey]map[int64]bool{},
+	}
+}
+
+// Score records the predictions made for a question.
+// Predictions for a question that ended in a tie are not counted.
+func (s *Scorer) Score(question ClosedQuestion, predictions []PredictionRecord) {
+	for _, prediction := range predictions {
+		majority := question.Majority(prediction.CountryCode)
+		if majority == 0 {
+			continue
+		}
+
+		console, ok := s.consoles[prediction.ConsoleID]
+		if !ok {
+			console = &PredictorScore{ConsoleID: prediction.ConsoleID}
+			s.consoles[prediction.ConsoleID] = console
+		}
+
+		key := regionKey{prediction.CountryCode, prediction.RegionID}
+		region, ok := s.regions[key]
+		if !ok {
+			region = &RegionStats{CountryCode: prediction.CountryCode, RegionID: prediction.RegionID}
+			s.regions[key] = region
+			s.predictors[key] = map[int64]bool{}
+		}
+
+		console.CountryCode = prediction.CountryCode
+		console.RegionID = prediction.RegionID
+		console.Predictions++
+		region.Predictions++
+		s.predictors[key][prediction.ConsoleID] = true
+
+		if prediction.Response == majority {
+			console.Correct++
+			console.CurrentStreak++
+			console.BestStreak = max(console.BestStreak, console.CurrentStreak)
+			region.Correct++
+		} else {
+			console.CurrentStreak = 0
+		}
+
+		console.Accuracy = float64(console.Correct) / float64(console.Predictions)
+		region.Accuracy = float64(region.Correct) / float64(region.Predictions)
+		region.Predictors = len(s.predictors[key])
+	}
+}
+
+// Leaderboard returns the most accurate consoles with at least minimumPredictions,
+// ranked by accuracy, then correct predictions, then best streak.
+// If countryCode is not 0, only consoles from that country are included.
+func (s *Scorer) Leaderboard(countryCode uint8, minimumPredictions int, limit int) []PredictorScore {
+	var scores []PredictorScore
+	for _, console := range s.consoles {
+		if console.Predictions < minimumPredictions || (countryCode != 0 && console.CountryCode != countryCode) {
+			continue
+		}
+
+		scores = append(scores, *console)
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		a, b := scores[i], scores[j]
+		if a.Accuracy != b.Accuracy {
+			return a.Accuracy > b.Accuracy
+		} else if a.Correct != b.Correct {
+			return a.Correct > b.Correct
+		} else if a.BestStreak != b.BestStreak {
+			return a.BestStreak > b.BestStreak
+		}
+
+		return a.ConsoleID < b.ConsoleID
+	})
+
+	if limit > 0 && len(scores) > limit {
+		scores = scores[:limit]
+	}
+
+	return scores
+}
+
+// Regions returns the statistics of every region predictions were made from, sorted by country and region.
+func (s *Scorer) Regions() []RegionStats {
+	regions := make([]RegionStats, 0, len(s.regions))
+	for _, region := range s.regions {
+		regions = append(regions, *region)
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].CountryCode != regions[j].CountryCode {
+			return regions[i].CountryCode < regions[j].CountryCode
+		}
+
+		return regions[i].RegionID < regions[j].RegionID
+	})
+
+	return regions
+}
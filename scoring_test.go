@@ -0,0 +1,62 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"testing"
+)
+
+func TestScorer(t *testing.T) {
+	scorer := NewScorer()
+
+	// The United Kingdom prefers response 1 and the Netherlands response 2, but response 1 wins worldwide.
+	votes := map[uint8]evc.Tally{
+		110: {MaleResponse1: 8, FemaleResponse2: 2},
+		94:  {MaleResponse2: 3, FemaleResponse2: 1},
+	}
+
+	national := ClosedQuestion{ID: 1, Votes: votes}
+	worldwide := ClosedQuestion{ID: 2, Worldwide: true, Votes: votes}
+	tied := ClosedQuestion{ID: 3, Votes: map[uint8]evc.Tally{110: {MaleResponse1: 1, MaleResponse2: 1}}}
+
+	scorer.Score(national, []PredictionRecord{
+		{ConsoleID: 1, CountryCode: 110, RegionID: 2, Response: 1},
+		{ConsoleID: 2, CountryCode: 94, RegionID: 3, Response: 2},
+		{ConsoleID: 3, CountryCode: 110, RegionID: 2, Response: 2},
+	})
+	scorer.Score(worldwide, []PredictionRecord{
+		{ConsoleID: 1, CountryCode: 110, RegionID: 2, Response: 1},
+		{ConsoleID: 2, CountryCode: 94, RegionID: 3, Response: 2},
+		{ConsoleID: 3, CountryCode: 110, RegionID: 2, Response: 1},
+	})
+	scorer.Score(tied, []PredictionRecord{{ConsoleID: 1, CountryCode: 110, RegionID: 2, Response: 2}})
+
+	leaderboard := scorer.Leaderboard(0, 2, 0)
+	if len(leaderboard) != 3 {
+		t.Fatalf("expected 3 consoles, got %+v", leaderboard)
+	}
+
+	first := leaderboard[0]
+	if first.ConsoleID != 1 || first.Correct != 2 || first.Accuracy != 1 || first.BestStreak != 2 || first.CurrentStreak != 2 {
+		t.Errorf("console 1 predicted both correctly and the tie should not count: %+v", first)
+	}
+
+	// Consoles 2 and 3 both have one correct prediction, but only console 3's is its current streak.
+	for _, score := range leaderboard[1:] {
+		if score.Correct != 1 || score.Predictions != 2 {
+			t.Errorf("unexpected score %+v", score)
+		}
+
+		if expected := map[int64]int{2: 0, 3: 1}[score.ConsoleID]; score.CurrentStreak != expected {
+			t.Errorf("console %d: expected a current streak of %d, got %d", score.ConsoleID, expected, score.CurrentStreak)
+		}
+	}
+
+	if netherlands := scorer.Leaderboard(94, 0, 0); len(netherlands) != 1 || netherlands[0].ConsoleID != 2 {
+		t.Errorf("expected only console 2 on the Netherlands leaderboard, got %+v", netherlands)
+	}
+
+	regions := scorer.Regions()
+	if len(regions) != 2 || regions[1].CountryCode != 110 || regions[1].Predictors != 2 || regions[1].Predictions != 4 || regions[1].Correct != 3 {
+		t.Errorf("unexpected region statistics %+v", regions)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sink stores published files.
+type Sink interface {
+	// Put stores a file, replacing any file at the same path.
+	Put(path string, data []byte) error
+	// Get returns a stored file. The error wraps os.ErrNotExist if there is none.
+	Get(path string) ([]byte, error)
+	Exists(path string) (bool, error)
+	// Close finishes writing. A sink must not be used once closed.
+	Close() error
+	String() string
+}
+
+// OpenSink opens the sink described by output, which is either a directory,
+// a .tar or .zip bundle, or an S3 bucket in the form s3://bucket/prefix.
+func OpenSink(output string) (Sink, error) {
+	switch {
+	case strings.HasPrefix(output, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(output, "s3://"), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("%s does not name a bucket", output)
+		}
+
+		config := GetConfig()
+		return NewS3Sink(config.S3, bucket, prefix)
+	case strings.HasSuffix(output, ".tar"), strings.HasSuffix(output, ".zip"):
+		return NewBundleSink(output), nil
+	default:
+		return NewFileSink(output), nil
+	}
+}
+
+// AtomicSink is a sink which stores every file of a publish before replacing any of them,
+// so a publish that fails part way leaves the published files as they were.
+type AtomicSink interface {
+	Sink
+	// PutAll stores files as Put does, replacing them only once all are stored.
+	PutAll(files map[string][]byte) error
+}
+
+// FileSink stores files in a local directory.
+type FileSink struct {
+	root string
+}
+
+func NewFileSink(root string) *FileSink {
+	return &FileSink{root: root}
+}
+
+// Put writes to a temporary file then renames it into place, so a half-written file is never visible.
+func (s *FileSink) Put(path string, data []byte) error {
+	return writeFileAtomic(filepath.Join(s.root, path), data)
+}
+
+func writeFileAtomic(destination string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(destination), 0755)
+	if err != nil {
+		return err
+	}
+
+	temp := filepath.Join(filepath.Dir(destination), "."+filepath.Base(destination)+".tmp")
+	err = os.WriteFile(temp, data, 0666)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp, destination)
+}
+
+// stagingDir is a sibling of the root, so staged files are never served but can be renamed into place.
+func (s *FileSink) stagingDir() string {
+	return filepath.Join(filepath.Dir(s.root), "."+filepath.Base(s.root)+"-staging")
+}
+
+// PutAll writes every file to a staging directory beside the root, then renames each into place,
+// with the manifest last. Nothing in the root changes unless every file was staged, and each file
+// is replaced by a rename, so clients never see a missing or half-written file.
+func (s *FileSink) PutAll(files map[string][]byte) error {
+	staging := s.stagingDir()
+	err := os.MkdirAll(staging, 0755)
+	if err != nil {
+		return err
+	}
+
+	staged, err := os.MkdirTemp(staging, "put-")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(staged)
+
+	paths := make([]string, 0, len(files))
+	for path, data := range files {
+		if err = writeFileAtomic(filepath.Join(staged, path), data); err != nil {
+			return err
+		}
+
+		if path != ManifestName {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+	if _, ok := files[ManifestName]; ok {
+		paths = append(paths, ManifestName)
+	}
+
+	for _, path := range paths {
+		destination := filepath.Join(s.root, path)
+		if err = os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+			return err
+		}
+
+		if err = os.Rename(filepath.Join(staged, path), destination); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *FileSink) Get(path string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.root, path))
+}
+
+func (s *FileSink) Exists(path string) (bool, error) {
+	_, err := os.Stat(filepath.Join(s.root, path))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (s *FileSink) Close() error {
+	return nil
+}
+
+func (s *FileSink) String() string {
+	return s.root
+}
+
+// BundleSink collects every file of a run into a single tar or zip file, written on Close.
+type BundleSink struct {
+	path  string
+	files map[string][]byte
+}
+
+func NewBundleSink(path string) *BundleSink {
+	return &BundleSink{path: path, files: map[string][]byte{}}
+}
+
+func (s *BundleSink) Put(path string, data []byte) error {
+	s.files[path] = data
+	return nil
+}
+
+// Get only returns files put during this run, as each run creates a new bundle.
+func (s *BundleSink) Get(path string) ([]byte, error) {
+	data, ok := s.files[path]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+
+	return data, nil
+}
+
+func (s *BundleSink) Exists(path string) (bool, error) {
+	_, ok := s.files[path]
+	return ok, nil
+}
+
+// Close writes the bundle, unless nothing was put.
+func (s *BundleSink) Close() error {
+	if len(s.files) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	file, err := os.Create(s.path + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(s.path, ".zip") {
+		err = s.writeZip(file, paths)
+	} else {
+		err = s.writeTar(file, paths)
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(s.path + ".tmp")
+		return err
+	}
+
+	return os.Rename(s.path+".tmp", s.path)
+}
+
+func (s *BundleSink) writeTar(writer io.Writer, paths []string) error {
+	modified := time.Now()
+	archive := tar.NewWriter(writer)
+	for _, path := range paths {
+		err := archive.WriteHeader(&tar.Header{
+			Name:    path,
+			Mode:    0644,
+			Size:    int64(len(s.files[path])),
+			ModTime: modified,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err = archive.Write(s.files[path]); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (s *BundleSink) writeZip(writer io.Writer, paths []string) error {
+	archive := zip.NewWriter(writer)
+	for _, path := range paths {
+		entry, err := archive.Create(path)
+		if err != nil {
+			return err
+		}
+
+		if _, err = entry.Write(s.files[path]); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (s *BundleSink) String() string {
+	return s.path
+}
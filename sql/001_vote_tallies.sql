@@ -0,0 +1,12 @@
+-- Explicit vote counts, replacing the ans_cnt column which packs a single digit count for
+-- male response 1, female response 1, male response 2 and female response 2 into each decimal place.
+-- Rows where these columns are NULL still use ans_cnt, and are converted when results are generated.
+ALTER TABLE votes
+    ADD COLUMN IF NOT EXISTS male_response1       INTEGER CHECK (male_response1 >= 0),
+    ADD COLUMN IF NOT EXISTS male_response2       INTEGER CHECK (male_response2 >= 0),
+    ADD COLUMN IF NOT EXISTS female_response1     INTEGER CHECK (female_response1 >= 0),
+    ADD COLUMN IF NOT EXISTS female_response2     INTEGER CHECK (female_response2 >= 0),
+    ADD COLUMN IF NOT EXISTS predictors_response1 INTEGER CHECK (predictors_response1 >= 0),
+    ADD COLUMN IF NOT EXISTS predictors_response2 INTEGER CHECK (predictors_response2 >= 0);
+
+ALTER TABLE votes ALTER COLUMN ans_cnt DROP NOT NULL;
@@ -0,0 +1,6 @@
+-- Votes which could not be placed in the detailed results, moved aside by running with -quarantine.
+CREATE TABLE IF NOT EXISTS votes_quarantine (
+    LIKE votes INCLUDING DEFAULTS,
+    reason         TEXT        NOT NULL,
+    quarantined_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+-- Japanese question text. The columns are appended so SELECT * keeps its existing order,
+-- and are NULL for questions which have not been translated, which are then shown in English.
+ALTER TABLE questions
+    ADD COLUMN IF NOT EXISTS question_japanese  TEXT,
+    ADD COLUMN IF NOT EXISTS response1_japanese TEXT,
+    ADD COLUMN IF NOT EXISTS response2_japanese TEXT;
@@ -0,0 +1,9 @@
+-- Translations approved by a moderator, which importing a translation file only replaces with --force.
+-- field is question, response1 or response2, and language is a code such as fr-CA.
+CREATE TABLE IF NOT EXISTS translation_approvals (
+    question_id INTEGER     NOT NULL,
+    language    TEXT        NOT NULL,
+    field       TEXT        NOT NULL,
+    approved_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (question_id, language, field)
+);
@@ -0,0 +1,4 @@
+-- The Wii number of the console which sent each vote or prediction, which the score command ranks consoles by.
+-- The voting server must fill it in for predictions to be scored. Rows where it is NULL still count towards
+-- the majority of a question, but their predictions are left out of the leaderboards.
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS wii_no BIGINT;
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TranslationFields are the texts of a question that are translated.
+var TranslationFields = []string{"question", "response1", "response2"}
+
+// TranslationUnit is a single text of a question in a translation file.
+type TranslationUnit struct {
+	QuestionID int
+	// Field is one of TranslationFields.
+	Field string
+	// Source is the English text.
+	Source string
+	// Target is the translation, which is empty if there is none.
+	Target   string
+	Approved bool
+	// Note is shown to translators.
+	Note string
+	// Unfinished targets are marked fuzzy or as needing translation, and are not imported.
+	Unfinished bool
+}
+
+// ID identifies the question and field of a unit in a translation file.
+func (u TranslationUnit) ID() string {
+	return fmt.Sprintf("%d/%s", u.QuestionID, u.Field)
+}
+
+func parseUnitID(id string) (int, string, error) {
+	questionStr, field, ok := strings.Cut(id, "/")
+	questionID, err := strconv.Atoi(questionStr)
+	if !ok || err != nil || !slices.Contains(TranslationFields, field) {
+		return 0, "", fmt.Errorf("invalid translation ID %q", id)
+	}
+
+	return questionID, field, nil
+}
+
+// WritePO writes units as a gettext PO file.
+func WritePO(writer io.Writer, language string, units []TranslationUnit) error {
+	buffered := bufio.NewWriter(writer)
+	fmt.Fprintf(buffered, "msgid \"\"\nmsgstr \"\"\n%s\n%s\n%s\n",
+		poQuote("Language: "+language+"\n"), poQuote("MIME-Version: 1.0\n"), poQuote("Content-Type: text/plain; charset=UTF-8\n"),
+	)
+
+	for _, unit := range units {
+		fmt.Fprintln(buffered)
+		if unit.Note != "" {
+			fmt.Fprintf(buffered, "#. %s\n", unit.Note)
+		}
+
+		if unit.Approved {
+			fmt.Fprintln(buffered, "#. Approved, so it is only replaced when imported with --force.")
+		}
+
+		fmt.Fprintf(buffered, "msgctxt %s\nmsgid %s\nmsgstr %s\n", poQuote(unit.ID()), poQuote(unit.Source), poQuote(unit.Target))
+	}
+
+	return buffered.Flush()
+}
+
+// poQuote quotes text as a PO string, splitting it after each line break as editors do.
+func poQuote(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 1 {
+		return `"` + replacer.Replace(text) + `"`
+	}
+
+	quoted := `""`
+	for _, line := range lines {
+		quoted += "\n\"" + replacer.Replace(line) + `"`
+	}
+
+	return quoted
+}
+
+func poUnquote(quoted string) (string, error) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", fmt.Errorf("expected a quoted string, got %s", quoted)
+	}
+
+	replacer := strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n", `\t`, "\t", `\r`, "\r")
+	return replacer.Replace(quoted[1 : len(quoted)-1]), nil
+}
+
+// ReadPO reads units written by WritePO, returning the language in its header.
+func ReadPO(reader io.Reader) (string, []TranslationUnit, error) {
+	var language string
+	var units []TranslationUnit
+
+	type entry struct {
+		fields map[string]string
+		fuzzy  bool
+	}
+
+	current := entry{fields: map[string]string{}}
+	var field string
+	finish := func() error {
+		defer func() { current = entry{fields: map[string]string{}} }()
+		if len(current.fields) == 0 {
+			return nil
+		}
+
+		if current.fields["msgid"] == "" {
+			// The header holds the metadata of the file.
+			for _, line := range strings.Split(current.fields["msgstr"], "\n") {
+				if value, ok := strings.CutPrefix(line, "Language:"); ok {
+					language = strings.TrimSpace(value)
+				}
+			}
+
+			return nil
+		}
+
+		questionID, unitField, err := parseUnitID(current.fields["msgctxt"])
+		if err != nil {
+			return err
+		}
+
+		units = append(units, TranslationUnit{
+			QuestionID: questionID,
+			Field:      unitField,
+			Source:     current.fields["msgid"],
+			Target:     current.fields["msgstr"],
+			Unfinished: current.fuzzy,
+		})
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		var err error
+		switch {
+		case line == "":
+			err = finish()
+			field = ""
+		case strings.HasPrefix(line, "#"):
+			// Comments come before an entry, so one after a msgstr starts the next entry, even without a blank line.
+			if _, ok := current.fields["msgstr"]; ok {
+				if err = finish(); err != nil {
+					break
+				}
+
+				field = ""
+			}
+
+			if strings.HasPrefix(line, "#,") {
+				current.fuzzy = current.fuzzy || strings.Contains(line, "fuzzy")
+			}
+		case strings.HasPrefix(line, `"`):
+			if field == "" {
+				err = errors.New("string outside of an entry")
+				break
+			}
+
+			var text string
+			text, err = poUnquote(line)
+			current.fields[field] += text
+		default:
+			keyword, quoted, _ := strings.Cut(line, " ")
+			switch keyword {
+			case "msgctxt", "msgid", "msgstr":
+				// A new msgctxt or msgid after a msgstr starts the next entry, even without a blank line.
+				if _, ok := current.fields["msgstr"]; ok && keyword != "msgstr" {
+					if err = finish(); err != nil {
+						break
+					}
+				}
+
+				field = keyword
+				current.fields[field], err = poUnquote(strings.TrimSpace(quoted))
+			default:
+				err = fmt.Errorf("unsupported keyword %s", keyword)
+			}
+		}
+
+		if err != nil {
+			return "", nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+
+	if err := finish(); err != nil {
+		return "", nil, err
+	}
+
+	return language, units, nil
+}
+
+// xliffDocument is an XLIFF 1.2 file with a single file element.
+type xliffDocument struct {
+	XMLName xml.Name  `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string    `xml:"version,attr"`
+	File    xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string      `xml:"original,attr"`
+	SourceLanguage string      `xml:"source-language,attr"`
+	TargetLanguage string      `xml:"target-language,attr"`
+	Datatype       string      `xml:"datatype,attr"`
+	Units          []xliffUnit `xml:"body>trans-unit"`
+}
+
+type xliffUnit struct {
+	ID       string      `xml:"id,attr"`
+	Approved string      `xml:"approved,attr,omitempty"`
+	Source   string      `xml:"source"`
+	Target   xliffTarget `xml:"target"`
+	Note     string      `xml:"note,omitempty"`
+}
+
+type xliffTarget struct {
+	State string `xml:"state,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+// WriteXLIFF writes units as an XLIFF 1.2 file.
+func WriteXLIFF(writer io.Writer, language string, units []TranslationUnit) error {
+	document := xliffDocument{
+		Version: "1.2",
+		File: xliffFile{
+			Original:       "questions",
+			SourceLanguage: "en",
+			TargetLanguage: language,
+			Datatype:       "plaintext",
+		},
+	}
+
+	for _, unit := range units {
+		xu := xliffUnit{ID: unit.ID(), Source: unit.Source, Target: xliffTarget{Text: unit.Target}, Note: unit.Note}
+		if unit.Target == "" {
+			xu.Target.State = "needs-translation"
+		} else {
+			xu.Target.State = "translated"
+		}
+
+		if unit.Approved {
+			xu.Approved = "yes"
+		}
+
+		document.File.Units = append(document.File.Units, xu)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// ReadXLIFF reads units written by WriteXLIFF, returning the target language of the file.
+func ReadXLIFF(reader io.Reader) (string, []TranslationUnit, error) {
+	var document xliffDocument
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return "", nil, err
+	}
+
+	var units []TranslationUnit
+	for _, xu := range document.File.Units {
+		questionID, field, err := parseUnitID(xu.ID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		units = append(units, TranslationUnit{
+			QuestionID: questionID,
+			Field:      field,
+			Source:     xu.Source,
+			Target:     xu.Target.Text,
+			Approved:   xu.Approved == "yes",
+			Note:       xu.Note,
+			Unfinished: xu.Target.State == "new" || xu.Target.State == "needs-translation",
+		})
+	}
+
+	return document.File.TargetLanguage, units, nil
+}
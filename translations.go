@@ -0,0 +1,414 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// QueryTranslationApprovals queries the approved translations of a language.
+	QueryTranslationApprovals = `SELECT question_id, field FROM translation_approvals WHERE language = $1`
+
+	// ApproveTranslation marks a translation as approved, as added by sql/004_translation_approvals.sql.
+	ApproveTranslation = `INSERT INTO translation_approvals (question_id, language, field) VALUES ($1, $2, $3)
+                    ON CONFLICT (question_id, language, field) DO UPDATE SET approved_at = now()`
+
+	// UnapproveTranslation removes the approval of a translation that has been replaced.
+	UnapproveTranslation = `DELETE FROM translation_approvals WHERE question_id = $1 AND language = $2 AND field = $3`
+)
+
+// translatedLanguages are the languages translated from English.
+var translatedLanguages = []evc.LanguageCode{
+	evc.Japanese, evc.German, evc.French, evc.Spanish, evc.Italian, evc.Dutch, evc.Portuguese, evc.FrenchCanadian,
+}
+
+// approvalKey identifies an approved translation within a language.
+type approvalKey struct {
+	questionID int
+	field      string
+}
+
+// TranslationChange is a translation to write to the questions table.
+type TranslationChange struct {
+	QuestionID int
+	Field      string
+	Text       string
+}
+
+// questionTexts returns the texts of a question in the order of TranslationFields.
+func questionTexts(question *evc.Question) []*evc.LocalizedText {
+	return []*evc.LocalizedText{&question.QuestionText, &question.Response1, &question.Response2}
+}
+
+// TranslationUnits returns the texts of a question to translate into the language.
+func TranslationUnits(question evc.Question, language evc.LanguageCode, approved map[approvalKey]bool, layouts evc.Layouts) []TranslationUnit {
+	var units []TranslationUnit
+	for i, text := range questionTexts(&question) {
+		layout := layouts.Response
+		if i == 0 {
+			layout = layouts.Question
+		}
+
+		units = append(units, TranslationUnit{
+			QuestionID: question.ID,
+			Field:      TranslationFields[i],
+			Source:     text.English,
+			Target:     text.Translation(language),
+			Approved:   approved[approvalKey{question.ID, TranslationFields[i]}],
+			Note: fmt.Sprintf("Starts %s. At most %d lines of %d characters, where full width characters count as two.",
+				question.Time.Format(time.DateOnly), layout.MaxLines, layout.Width),
+		})
+	}
+
+	return units
+}
+
+// PlanImport validates the imported units of a question as they would be generated,
+// returning the translations which change. Approved translations are only replaced if force is set.
+func PlanImport(question evc.Question, language evc.LanguageCode, units []TranslationUnit, approved map[approvalKey]bool, force bool, charset *evc.Charset, layouts evc.Layouts) ([]TranslationChange, error) {
+	if language == evc.English {
+		return nil, errors.New("English is the source language and cannot be imported")
+	}
+
+	current := questionTexts(&question)
+	imported := evc.Question{ID: question.ID, Time: question.Time}
+	importedTexts := questionTexts(&imported)
+
+	var errs []error
+	var fields []int
+	for _, unit := range units {
+		if unit.Unfinished || strings.TrimSpace(unit.Target) == "" {
+			continue
+		}
+
+		i := slices.Index(TranslationFields, unit.Field)
+		if unit.Source != "" && strings.TrimSpace(unit.Source) != strings.TrimSpace(current[i].English) {
+			errs = append(errs, fmt.Errorf("question %d %s: the English text has changed since it was exported", question.ID, unit.Field))
+			continue
+		}
+
+		importedTexts[i].Set(language, strings.TrimSpace(unit.Target))
+		fields = append(fields, i)
+	}
+
+	if charset == nil {
+		charset = &evc.DefaultCharset
+	}
+
+	// Characters are substituted in what is stored, but wrapping is left to generation.
+	errs = append(errs, charset.CheckQuestion(&imported))
+	check := imported
+	if language == evc.Japanese {
+		errs = append(errs, check.CheckJapanese()...)
+	}
+
+	dropped, err := check.SanitizeText(layouts)
+	errs = append(append(errs, dropped...), err)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var changes []TranslationChange
+	for _, i := range fields {
+		text := importedTexts[i].Translation(language)
+		previous := current[i].Translation(language)
+		if text == previous {
+			continue
+		}
+
+		if previous != "" && approved[approvalKey{question.ID, TranslationFields[i]}] && !force {
+			errs = append(errs, fmt.Errorf("question %d %s %s is approved, import with --force to replace it", question.ID, language, TranslationFields[i]))
+			continue
+		}
+
+		changes = append(changes, TranslationChange{QuestionID: question.ID, Field: TranslationFields[i], Text: text})
+	}
+
+	return changes, errors.Join(errs...)
+}
+
+// translationColumn returns the position of a translation in a row of the questions table, as read by scanQuestion.
+func translationColumn(language evc.LanguageCode, field string) int {
+	fieldIndex := slices.Index(TranslationFields, field)
+	if language == evc.Japanese {
+		return 28 + fieldIndex
+	}
+
+	languages := []evc.LanguageCode{evc.English, evc.German, evc.French, evc.Spanish, evc.Italian, evc.Dutch, evc.Portuguese, evc.FrenchCanadian}
+	return 1 + fieldIndex*len(languages) + slices.Index(languages, language)
+}
+
+// questionColumns returns the names of the columns of the questions table, in the order scanQuestion reads them.
+func questionColumns(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	rows, err := pool.Query(ctx, `SELECT * FROM questions LIMIT 0`)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	for _, field := range rows.FieldDescriptions() {
+		columns = append(columns, string(field.Name))
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(columns) < 31 {
+		return nil, errors.New("the questions table has no Japanese columns, apply sql/003_japanese_text.sql")
+	}
+
+	return columns, nil
+}
+
+func queryApprovals(ctx context.Context, pool *pgxpool.Pool, language evc.LanguageCode) (map[approvalKey]bool, error) {
+	rows, err := pool.Query(ctx, QueryTranslationApprovals, language.String())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	approved := map[approvalKey]bool{}
+	for rows.Next() {
+		var key approvalKey
+		if err = rows.Scan(&key.questionID, &key.field); err != nil {
+			return nil, err
+		}
+
+		approved[key] = true
+	}
+
+	return approved, rows.Err()
+}
+
+// RunTranslations exports translation files for translators, and imports them back into the questions table.
+func RunTranslations(args []string, currentTime time.Time, charset *evc.Charset, layouts evc.Layouts, dryRun bool) {
+	if len(args) == 0 {
+		checkError(errors.New("translations requires export or import"))
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "export":
+		flags := flag.NewFlagSet("translations export", flag.ExitOnError)
+		format := flags.String("format", "po", "file format (po or xliff)")
+		out := flags.String("out", "translations", "directory to write a file per language to")
+		weeks := flags.Int("weeks", 4, "number of weeks of scheduled questions to export")
+		checkError(flags.Parse(args[1:]))
+
+		pool := ConnectDatabase(ctx)
+		defer pool.Close()
+		checkError(exportTranslations(ctx, pool, *format, *out, currentTime, *weeks, layouts))
+	case "import":
+		flags := flag.NewFlagSet("translations import", flag.ExitOnError)
+		force := flags.Bool("force", false, "replace approved translations")
+		approve := flags.Bool("approve", false, "mark the imported translations as approved")
+		checkError(flags.Parse(args[1:]))
+
+		if flags.NArg() == 0 {
+			checkError(errors.New("translations import requires at least one file"))
+		}
+
+		pool := ConnectDatabase(ctx)
+		defer pool.Close()
+
+		failed := false
+		for _, path := range flags.Args() {
+			if err := importTranslations(ctx, pool, path, charset, layouts, *force, *approve, dryRun); err != nil {
+				fmt.Printf("%s: %v\n", path, err)
+				failed = true
+			}
+		}
+
+		if failed {
+			pool.Close()
+			os.Exit(1)
+		}
+	default:
+		checkError(fmt.Errorf("unknown translations command %q", args[0]))
+	}
+}
+
+func exportTranslations(ctx context.Context, pool *pgxpool.Pool, format string, out string, currentTime time.Time, weeks int, layouts evc.Layouts) error {
+	write, extension := WritePO, ".po"
+	if format == "xliff" {
+		write, extension = WriteXLIFF, ".xlf"
+	} else if format != "po" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	rows, err := pool.Query(ctx, QueryScheduledQuestions, currentTime, currentTime.AddDate(0, 0, 7*weeks))
+	if err != nil {
+		return err
+	}
+
+	var questions []evc.Question
+	for rows.Next() {
+		question, err := scanQuestion(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		questions = append(questions, question)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+
+	for _, language := range translatedLanguages {
+		approved, err := queryApprovals(ctx, pool, language)
+		if err != nil {
+			return err
+		}
+
+		var units []TranslationUnit
+		for _, question := range questions {
+			units = append(units, TranslationUnits(question, language, approved, layouts)...)
+		}
+
+		path := filepath.Join(out, language.String()+extension)
+		if err = writeTranslationFile(path, language, units, write); err != nil {
+			return err
+		}
+
+		logger.Info("exported translations", "path", path, "questions", len(questions))
+	}
+
+	return nil
+}
+
+func writeTranslationFile(path string, language evc.LanguageCode, units []TranslationUnit, write func(io.Writer, string, []TranslationUnit) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(file, language.String(), units)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// importTranslations writes the translations of a file back to the questions table.
+// Nothing is written if any question in the file fails validation.
+func importTranslations(ctx context.Context, pool *pgxpool.Pool, path string, charset *evc.Charset, layouts evc.Layouts, force bool, approve bool, dryRun bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	read := ReadPO
+	if extension := filepath.Ext(path); extension == ".xlf" || extension == ".xliff" {
+		read = ReadXLIFF
+	}
+
+	languageStr, units, err := read(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	language, err := evc.ParseLanguageCode(languageStr)
+	if err != nil {
+		return err
+	}
+
+	approved, err := queryApprovals(ctx, pool, language)
+	if err != nil {
+		return err
+	}
+
+	byQuestion := map[int][]TranslationUnit{}
+	var questionIDs []int
+	for _, unit := range units {
+		if _, ok := byQuestion[unit.QuestionID]; !ok {
+			questionIDs = append(questionIDs, unit.QuestionID)
+		}
+
+		byQuestion[unit.QuestionID] = append(byQuestion[unit.QuestionID], unit)
+	}
+
+	var changes []TranslationChange
+	var errs []error
+	for _, questionID := range questionIDs {
+		question, err := scanQuestion(pool.QueryRow(ctx, QueryQuestion, questionID))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("question %d: %w", questionID, err))
+			continue
+		}
+
+		questionChanges, err := PlanImport(question, language, byQuestion[questionID], approved, force, charset, layouts)
+		errs = append(errs, err)
+		changes = append(changes, questionChanges...)
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		fmt.Printf("question %d %s %s: %q\n", change.QuestionID, language, change.Field, change.Text)
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run, %d translations would be imported.\n", len(changes))
+		return nil
+	}
+
+	columns, err := questionColumns(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+	for _, change := range changes {
+		column := pgx.Identifier{columns[translationColumn(language, change.Field)]}.Sanitize()
+		_, err = tx.Exec(ctx, fmt.Sprintf("UPDATE questions SET %s = $1 WHERE question_id = $2", column), change.Text, change.QuestionID)
+		if err != nil {
+			return err
+		}
+
+		// Replaced text must be approved again.
+		approval := UnapproveTranslation
+		if approve {
+			approval = ApproveTranslation
+		}
+
+		if _, err = tx.Exec(ctx, approval, change.QuestionID, language.String(), change.Field); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	logger.Info("imported translations", "path", path, "language", language, "translations", len(changes))
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"EverybodyVotesChannel/evc"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranslationFiles(t *testing.T) {
+	units := []TranslationUnit{
+		{QuestionID: 8, Field: "question", Source: "Is \"cats\"\nor dogs\\better?", Target: "« Chats »\nou chiens ?", Note: "Starts 2025-05-08."},
+		{QuestionID: 8, Field: "response1", Source: "Cats", Target: "Chats", Approved: true},
+		{QuestionID: 8, Field: "response2", Source: "Dogs"},
+	}
+
+	for _, format := range []struct {
+		name  string
+		write func(*bytes.Buffer) error
+		read  func(*bytes.Buffer) (string, []TranslationUnit, error)
+	}{
+		{"po", func(b *bytes.Buffer) error { return WritePO(b, "fr", units) }, func(b *bytes.Buffer) (string, []TranslationUnit, error) { return ReadPO(b) }},
+		{"xliff", func(b *bytes.Buffer) error { return WriteXLIFF(b, "fr", units) }, func(b *bytes.Buffer) (string, []TranslationUnit, error) { return ReadXLIFF(b) }},
+	} {
+		var buffer bytes.Buffer
+		if err := format.write(&buffer); err != nil {
+			t.Fatal(err)
+		}
+
+		language, read, err := format.read(&buffer)
+		if err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+
+		if language != "fr" || len(read) != len(units) {
+			t.Fatalf("%s: read %d units in %q", format.name, len(read), language)
+		}
+
+		for i, unit := range read {
+			if unit.ID() != units[i].ID() || unit.Source != units[i].Source || unit.Target != units[i].Target {
+				t.Errorf("%s: read %+v, expected %+v", format.name, unit, units[i])
+			}
+		}
+
+		if format.name == "xliff" && (!read[1].Approved || !read[2].Unfinished) {
+			t.Errorf("xliff: approval and state were not read: %+v", read)
+		}
+	}
+
+	// Fuzzy entries are read but not imported.
+	_, read, err := ReadPO(strings.NewReader("#, fuzzy\nmsgctxt \"8/response2\"\nmsgid \"Dogs\"\nmsgstr \"Chiens\"\n"))
+	if err != nil || len(read) != 1 || !read[0].Unfinished {
+		t.Errorf("expected an unfinished unit, got %+v, %v", read, err)
+	}
+
+	// A fuzzy flag belongs to the entry after it, even without a blank line between them.
+	_, read, err = ReadPO(strings.NewReader("msgctxt \"8/response1\"\nmsgid \"Cats\"\nmsgstr \"Chats\"\n#, fuzzy\nmsgctxt \"8/response2\"\nmsgid \"Dogs\"\nmsgstr \"Chiens\"\n"))
+	if err != nil || len(read) != 2 || read[0].Unfinished || !read[1].Unfinished {
+		t.Errorf("expected only the second unit to be unfinished, got %+v, %v", read, err)
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	question := evc.Question{
+		ID:           8,
+		QuestionText: evc.LocalizedText{English: "Cats or dogs?", French: "Chats ou chiens ?"},
+		Response1:    evc.LocalizedText{English: "Cats", French: "Chats"},
+		Response2:    evc.LocalizedText{English: "Dogs"},
+		Time:         time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	units := TranslationUnits(question, evc.French, nil, evc.DefaultLayouts)
+	units[0].Target = "Chats ou chiens ?"
+	units[1].Target = "Les chats"
+	units[2].Target = "Chiens’"
+
+	approved := map[approvalKey]bool{{8, "response1"}: true}
+	_, err := PlanImport(question, evc.French, units, approved, false, nil, evc.DefaultLayouts)
+	if err == nil || !strings.Contains(err.Error(), "question 8 fr response1 is approved") {
+		t.Errorf("expected approved text to be kept, got %v", err)
+	}
+
+	changes, err := PlanImport(question, evc.French, units, approved, true, nil, evc.DefaultLayouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The unchanged question is left alone, and characters are substituted.
+	expected := []TranslationChange{{8, "response1", "Les chats"}, {8, "response2", "Chiens'"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %+v, expected %+v", changes, expected)
+	}
+
+	units[2].Target = "Chiens 🐕"
+	_, err = PlanImport(question, evc.French, units, approved, true, nil, evc.DefaultLayouts)
+	if !errors.As(err, &evc.CharsetError{}) {
+		t.Errorf("expected a character error, got %v", err)
+	}
+
+	units[2].Target = strings.Repeat("Chiens ", 10)
+	if _, err = PlanImport(question, evc.French, units, approved, true, nil, evc.DefaultLayouts); !errors.Is(err, evc.ErrTextTooLong) {
+		t.Errorf("expected text that does not fit to be refused, got %v", err)
+	}
+
+	units[2].Target = "Chiens"
+	units[2].Source = "Puppies"
+	if _, err = PlanImport(question, evc.French, units, approved, true, nil, evc.DefaultLayouts); err == nil {
+		t.Errorf("expected a translation of old English text to be refused")
+	}
+}
+
+func TestTranslationColumn(t *testing.T) {
+	for _, test := range []struct {
+		language evc.LanguageCode
+		field    string
+		column   int
+	}{
+		{evc.German, "question", 2},
+		{evc.FrenchCanadian, "question", 8},
+		{evc.French, "response1", 11},
+		{evc.FrenchCanadian, "response2", 24},
+		{evc.Japanese, "question", 28},
+		{evc.Japanese, "response2", 30},
+	} {
+		if column := translationColumn(test.language, test.field); column != test.column {
+			t.Errorf("%s %s: got column %d, expected %d", test.language, test.field, column, test.column)
+		}
+	}
+}